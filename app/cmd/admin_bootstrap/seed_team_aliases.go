@@ -0,0 +1,79 @@
+package admin_bootstrap
+
+import (
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+type teamAliasSeed struct {
+	Provider     string
+	Alias        string
+	Abbreviation string
+}
+
+// older abbreviations some feeds still send
+var extraTeamAliasSeeds = []teamAliasSeed{
+	{Provider: service.TeamAliasProviderAny, Alias: "WAS", Abbreviation: "WSH"},
+	{Provider: service.TeamAliasProviderAny, Alias: "JAC", Abbreviation: "JAX"},
+}
+
+// teamAliasSeeds are the default aliases: the Odds API uses "City Name" for every team
+func teamAliasSeeds() []teamAliasSeed {
+	seeds := make([]teamAliasSeed, 0, len(seedTeams)+len(extraTeamAliasSeeds))
+	for _, team := range seedTeams {
+		seeds = append(seeds, teamAliasSeed{
+			Provider:     external.ProviderOdds,
+			Alias:        team.City + " " + team.Name,
+			Abbreviation: team.Abbreviation,
+		})
+	}
+	return append(seeds, extraTeamAliasSeeds...)
+}
+
+// SeedTeamAliases adds the default team aliases when there are none.  Once an admin has aliases they're
+// left alone, so a deleted default doesn't come back
+func SeedTeamAliases(db *sqlx.DB) error {
+	var count int
+
+	err := db.Get(&count, `SELECT COUNT(*) FROM public.team_aliases`)
+	if err != nil {
+		logger.Error("Seed Team Aliases--Error Getting team aliases")
+		return err
+	}
+
+	if count > 0 {
+		logger.Info("Team alias info already exists")
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, seed := range teamAliasSeeds() {
+		aliasID, err := id.New()
+		if err != nil {
+			return err
+		}
+
+		// teams that don't exist are skipped
+		_, err = tx.Exec(`
+			INSERT INTO public.team_aliases (id, provider, alias, team_id)
+			SELECT $1, $2, $3, id
+			FROM public.teams
+			WHERE abbreviation = $4
+			ON CONFLICT DO NOTHING
+		`, aliasID, seed.Provider, seed.Alias, seed.Abbreviation)
+		if err != nil {
+			logger.Error("Error seeding team alias", "alias", seed.Alias, "error", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -14,6 +21,9 @@ import (
 	"pawked.com/sendyourpicks/internal/logger"
 )
 
+// how long in-flight requests get to finish once we're told to stop
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 
 	// Load .env from repo root if present; in containers env vars come from the runtime.
@@ -56,6 +66,9 @@ func main() {
 	// Register database connection pool metrics
 	middleware.RegisterDBMetrics(dbx)
 
+	// Register Odds API quota metrics
+	middleware.RegisterOddsMetrics()
+
 	// seed teams
 	logger.Info("checking team table data")
 	if err := admin_bootstrap.SeedTeams(dbx); err != nil {
@@ -64,6 +77,12 @@ func main() {
 	}
 	logger.Info("team seed check complete")
 
+	// seed default provider team aliases
+	if err := admin_bootstrap.SeedTeamAliases(dbx); err != nil {
+		logger.Error("Team alias seeding failed", "error", err)
+		os.Exit(1)
+	}
+
 	// seed default global settings table
 	logger.Info("checking default settings table")
 	if err := admin_bootstrap.SeedGlobalSettings(dbx); err != nil {
@@ -71,6 +90,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	// background scheduler that advances active seasons without a commissioner pressing the button
+	schedulerCfg, err := loadSchedulerConfig()
+	if err != nil {
+		logger.Error("Failed to load scheduler configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// canceled on ctrl-c or SIGTERM so the scheduler and the HTTP server can stop cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var scheduler sync.WaitGroup
+	if schedulerCfg != nil {
+		scheduler.Add(1)
+		go func() {
+			defer scheduler.Done()
+			runScheduler(ctx, dbx, schedulerCfg)
+		}()
+	} else {
+		logger.Info("scheduler disabled")
+	}
+
 	// create the Gin router with default logger and recovery
 	router := gin.Default()
 
@@ -93,10 +134,43 @@ func main() {
 		addr = ":8080"
 	}
 
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
+
 	// start serving
 	logger.Info("starting HTTP server", "port", addr)
-	if err := router.Run(addr); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	// run until we're told to stop or the server fails
+	failed := false
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	case err := <-serverErr:
 		logger.Error("server initialization failed", "error", err)
+		failed = true
+		stop()
+	}
+
+	// let in-flight requests finish, then wait for the scheduler to let go of its lock
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("HTTP server shutdown failed", "error", err)
+	}
+	scheduler.Wait()
+
+	if err := dbx.Close(); err != nil {
+		logger.Error("closing database failed", "error", err)
+	}
+	logger.Info("shutdown complete")
+
+	if failed {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+// arbitrary key for pg_try_advisory_lock so only one replica runs the scheduler at a time
+const schedulerLockKey int64 = 7_462_019_001
+
+// how many times a single tick will try to push a season forward before waiting for the next one
+const maxStepsPerTick = 10
+
+type schedulerConfig struct {
+	UserID          string
+	Interval        time.Duration
+	GameDayInterval time.Duration
+
+	// how often each active week's bookmaker lines are added to the spread history
+	LineHistoryInterval time.Duration
+}
+
+// loadSchedulerConfig reads the SCHEDULER_* env vars.  Returns nil if the scheduler isn't enabled
+func loadSchedulerConfig() (*schedulerConfig, error) {
+	enabled, _ := strconv.ParseBool(os.Getenv("SCHEDULER_ENABLED"))
+	if !enabled {
+		return nil, nil
+	}
+
+	// anything the scheduler creates needs someone to be created_by, so it acts as a real profile
+	userID := os.Getenv("SCHEDULER_USER_ID")
+	if userID == "" {
+		return nil, fmt.Errorf("SCHEDULER_USER_ID must be set when SCHEDULER_ENABLED is true")
+	}
+
+	interval, err := durationFromEnv("SCHEDULER_INTERVAL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	gameDayInterval, err := durationFromEnv("SCHEDULER_GAMEDAY_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	lineHistoryInterval, err := durationFromEnv("SCHEDULER_LINE_HISTORY_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulerConfig{
+		UserID:              userID,
+		Interval:            interval,
+		GameDayInterval:     gameDayInterval,
+		LineHistoryInterval: lineHistoryInterval,
+	}, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive duration like 10m", key, raw)
+	}
+	return d, nil
+}
+
+// runScheduler advances every active season on a timer until ctx is cancelled
+func runScheduler(ctx context.Context, db *sqlx.DB, cfg *schedulerConfig) {
+	logger.Info(
+		"scheduler started",
+		"interval", cfg.Interval.String(),
+		"gameday_interval", cfg.GameDayInterval.String(),
+	)
+
+	// when each active week's lines were last added to the spread history
+	linesRecordedAt := map[string]time.Time{}
+
+	for {
+		schedulerTick(ctx, db, cfg, linesRecordedAt)
+
+		// poll faster while games are being played so results come in quickly
+		wait := cfg.Interval
+		inWindow, err := service.IsGameWindow(db, time.Now())
+		if err != nil {
+			logger.Error("scheduler failed to check game window", "error", err)
+		} else if inWindow {
+			wait = cfg.GameDayInterval
+		}
+
+		logger.Debug("scheduler sleeping", "wait", wait.String())
+
+		select {
+		case <-ctx.Done():
+			logger.Info("scheduler stopped")
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// schedulerTick grabs the advisory lock, advances each active season as far as it will go and records the
+// active weeks' lines
+func schedulerTick(ctx context.Context, db *sqlx.DB, cfg *schedulerConfig, linesRecordedAt map[string]time.Time) {
+	// advisory locks belong to a session, so hold one connection for the whole tick
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		logger.Error("scheduler failed to get a connection", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.GetContext(ctx, &locked, `SELECT pg_try_advisory_lock($1)`, schedulerLockKey); err != nil {
+		logger.Error("scheduler failed to take advisory lock", "error", err)
+		return
+	}
+	if !locked {
+		logger.Debug("scheduler lock held by another replica, skipping tick")
+		return
+	}
+	defer func() {
+		// use a fresh context so the unlock still happens during shutdown
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, schedulerLockKey); err != nil {
+			logger.Error("scheduler failed to release advisory lock", "error", err)
+		}
+	}()
+
+	seasonIDs, err := service.GetActiveSeasonIDs(db)
+	if err != nil {
+		logger.Error("scheduler failed to load active seasons", "error", err)
+		return
+	}
+
+	for _, seasonID := range seasonIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		advanceSeasonUntilBlocked(ctx, db, seasonID, cfg.UserID)
+	}
+
+	recordActiveWeekLines(ctx, db, cfg.LineHistoryInterval, linesRecordedAt)
+}
+
+// recordActiveWeekLines adds the current bookmaker lines to the spread history for active weeks that haven't
+// been recorded in the last interval, so the line movement view keeps up after activation
+func recordActiveWeekLines(ctx context.Context, db *sqlx.DB, interval time.Duration, linesRecordedAt map[string]time.Time) {
+	weekIDs, err := service.GetActiveWeekIDs(db)
+	if err != nil {
+		logger.Error("scheduler failed to load active weeks", "error", err)
+		return
+	}
+
+	// forget weeks that aren't active anymore
+	active := map[string]bool{}
+	for _, weekID := range weekIDs {
+		active[weekID] = true
+	}
+	for weekID := range linesRecordedAt {
+		if !active[weekID] {
+			delete(linesRecordedAt, weekID)
+		}
+	}
+
+	for _, weekID := range weekIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(linesRecordedAt[weekID]) < interval {
+			continue
+		}
+
+		res, err := service.RecordWeekSpreadHistory(ctx, db, weekID)
+		switch {
+		case errors.Is(err, external.ErrOddsAPIKeyMissing):
+			logger.Debug("scheduler not recording line history, no Odds API key")
+			return
+		case errors.Is(err, external.ErrOddsQuotaLow):
+			logger.Warn("scheduler not recording line history, Odds API quota is low", "odds_quota", external.CurrentOddsQuota())
+			return
+		case err != nil:
+			logger.Error("scheduler failed to record line history", "week_id", weekID, "error", err)
+			continue
+		}
+		linesRecordedAt[weekID] = time.Now()
+
+		logger.Info(
+			"scheduler recorded line history",
+			"week_id", weekID,
+			"games_matched", res.GamesMatched,
+			"lines_recorded", res.LinesRecorded,
+		)
+	}
+}
+
+// advanceSeasonUntilBlocked keeps stepping a season until it needs a commissioner, is waiting on games, or is done
+func advanceSeasonUntilBlocked(ctx context.Context, db *sqlx.DB, seasonID string, userID string) {
+	lastWeekID, lastStatus := "", ""
+
+	for step := 0; step < maxStepsPerTick; step++ {
+		res, err := service.AdvanceSeason(ctx, db, seasonID, userID)
+		if err != nil {
+			logger.Error("scheduler failed to advance season", "season_id", seasonID, "error", err)
+			return
+		}
+
+		switch res.Action {
+		case service.AdvanceActionSeasonComplete,
+			service.AdvanceActionManualActionRequired,
+			service.AdvanceActionWaitingForGames:
+			// these are expected stopping points
+			logger.Debug(
+				"scheduler stopped advancing season",
+				"season_id", seasonID,
+				"action", res.Action,
+				"week_id", res.WeekID,
+				"status", res.Status,
+			)
+			return
+		}
+
+		// guard against a state that "advances" without actually changing
+		if res.WeekID == lastWeekID && res.Status == lastStatus {
+			logger.Warn("scheduler made no progress on season", "season_id", seasonID, "week_id", res.WeekID, "status", res.Status)
+			return
+		}
+		lastWeekID, lastStatus = res.WeekID, res.Status
+
+		logger.Info("scheduler advanced week", "season_id", seasonID, "week_id", res.WeekID, "status", res.Status)
+	}
+}
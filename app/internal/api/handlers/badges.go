@@ -10,12 +10,21 @@ import (
 	"pawked.com/sendyourpicks/internal/service"
 )
 
-// GetBadges returns badges for all users based on the active season.
+// GetBadges returns badges for all users based on the active pick'em season in the route's league,
+// or ?league_id= (the default league if neither is set).  Only the league's members can see them
 func GetBadges(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// find the active season
+		leagueID := requestLeagueID(c)
+		if leagueID == "" {
+			leagueID = service.DefaultLeagueID
+		}
+		if !requireLeagueViewer(db, c, leagueID) {
+			return
+		}
+
+		// find the league's active pick'em season
 		var seasonID string
-		err := db.Get(&seasonID, `SELECT id FROM public.seasons WHERE is_active = true LIMIT 1`)
+		err := db.Get(&seasonID, `SELECT id FROM public.seasons WHERE is_active = true AND league_id = $1 AND season_type = $2 LIMIT 1`, leagueID, service.SeasonTypePickem)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusOK, gin.H{"badges": map[string][]models.Badge{}})
@@ -35,7 +35,7 @@ func GetSeasonHistory(db *sqlx.DB) gin.HandlerFunc {
 			UserID     string    `db:"user_id" json:"user_id"`
 			Username   string    `db:"username" json:"username"`
 			WeekID     string    `db:"week_id" json:"week_id"`
-			Points     int       `db:"points" json:"points"`
+			Points     float64   `db:"points" json:"points"`
 			Rank       int       `db:"rank" json:"rank"`
 			ComputedAt time.Time `db:"computed_at" json:"computed_at"`
 		}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/api/middleware"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+// CorrectGameResult fixes a game's final score after its week has been played (usually because the provider corrected it)
+// and recomputes pick results, week results and season standings from that week on
+func CorrectGameResult(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		gameID := c.Param("game_id")
+		if gameID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing game ID"})
+			return
+		}
+
+		var req struct {
+			HomeScore *int `json:"home_score" binding:"required"`
+			AwayScore *int `json:"away_score" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if *req.HomeScore < 0 || *req.AwayScore < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scores can't be negative"})
+			return
+		}
+
+		res, err := service.CorrectGameResult(c.Request.Context(), db, gameID, *req.HomeScore, *req.AwayScore)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrGameNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found", "game_id": gameID})
+			case errors.Is(err, service.ErrWeekNotCorrectable):
+				c.JSON(http.StatusConflict, gin.H{"error": "Game's week hasn't been played yet.  Scores are still imported automatically"})
+			case errors.Is(err, service.ErrGameVoided):
+				c.JSON(http.StatusConflict, gin.H{"error": "Game is voided and doesn't count for anyone"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to correct game result"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"game_id":             res.GameID,
+			"week_id":             res.WeekID,
+			"previous_home_score": res.PreviousHomeScore,
+			"previous_away_score": res.PreviousAwayScore,
+			"home_score":          res.HomeScore,
+			"away_score":          res.AwayScore,
+			"picks_updated":       res.PicksUpdated,
+			"weeks_rescored":      res.WeeksRescored,
+			"standings_rebuilt":   res.WeeksResnapshot,
+		})
+	}
+}
+
+// VoidGame takes a game out of scoring for everyone (postponed and never made up, forfeits, etc).
+// If the week was already graded, results and standings are recalculated
+func VoidGame(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		gameID := c.Param("game_id")
+		if gameID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing game ID"})
+			return
+		}
+
+		var req struct {
+			Reason string `json:"reason" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body.  reason is required"})
+			return
+		}
+
+		res, err := service.VoidGame(c.Request.Context(), db, gameID, req.Reason, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrGameNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found", "game_id": gameID})
+			case errors.Is(err, service.ErrVoidReasonRequired):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+			case errors.Is(err, service.ErrGameAlreadyVoided):
+				c.JSON(http.StatusConflict, gin.H{"error": "Game is already voided"})
+			case errors.Is(err, service.ErrWeekNotStarted):
+				c.JSON(http.StatusConflict, gin.H{"error": "Games can only be voided once their week is active"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to void game"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"game_id":           res.GameID,
+			"week_id":           res.WeekID,
+			"picks_updated":     res.PicksUpdated,
+			"weeks_rescored":    res.WeeksRescored,
+			"standings_rebuilt": res.WeeksResnapshot,
+		})
+	}
+}
+
+// ImportManualGames adds games a provider is missing to a week from an upload.  Send JSON {"games": [...]},
+// a text/csv body, or a multipart form with the CSV in "file".  Nothing is written unless every row is valid;
+// otherwise the response lists the problem with each row
+func ImportManualGames(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		var games []service.ManualGameInput
+		var parseErrors []service.ManualImportRowError
+		var err error
+
+		switch c.ContentType() {
+		case "text/csv":
+			games, parseErrors, err = service.ParseManualGamesCSV(c.Request.Body)
+		case "multipart/form-data":
+			file, fileErr := c.FormFile("file")
+			if fileErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV file.  Upload it as \"file\""})
+				return
+			}
+			upload, openErr := file.Open()
+			if openErr != nil {
+				c.Error(openErr)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read upload"})
+				return
+			}
+			defer upload.Close()
+			games, parseErrors, err = service.ParseManualGamesCSV(upload)
+		default:
+			var req struct {
+				Games []service.ManualGameInput `json:"games" binding:"required"`
+			}
+			err = c.ShouldBindJSON(&req)
+			games = req.Games
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload", "details": err.Error()})
+			return
+		}
+
+		res, err := service.ImportManualGames(c.Request.Context(), db, weekID, games, parseErrors, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrManualImportRows):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":  "Some rows are invalid.  Nothing was imported",
+					"errors": res.Errors,
+				})
+			case errors.Is(err, service.ErrNoManualGames):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "No games to import"})
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+			case errors.Is(err, service.ErrWeekGamesLocked):
+				c.JSON(http.StatusConflict, gin.H{"error": "Games can only be added before the week is activated"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import games"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// CreateGame adds a single game to a week before it's activated
+func CreateGame(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		var req service.GameInput
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body.  home_team, away_team and kickoff_time are required"})
+			return
+		}
+
+		game, err := service.CreateGame(c.Request.Context(), db, weekID, req, userID)
+		if err != nil {
+			if errors.Is(err, service.ErrWeekNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+				return
+			}
+			respondGameEditError(c, err, "Failed to create game")
+			return
+		}
+
+		c.JSON(http.StatusCreated, game)
+	}
+}
+
+// UpdateGame edits a game's teams, kickoff time, neutral site flag or external id before its week is activated
+func UpdateGame(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		gameID := c.Param("game_id")
+		if gameID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing game ID"})
+			return
+		}
+
+		var req service.GameUpdate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		game, err := service.UpdateGame(c.Request.Context(), db, gameID, req, userID)
+		if err != nil {
+			if errors.Is(err, service.ErrGameNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found", "game_id": gameID})
+				return
+			}
+			respondGameEditError(c, err, "Failed to update game")
+			return
+		}
+
+		c.JSON(http.StatusOK, game)
+	}
+}
+
+// DeleteGame removes a game from a week before it's activated
+func DeleteGame(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		gameID := c.Param("game_id")
+		if gameID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing game ID"})
+			return
+		}
+
+		if err := service.DeleteGame(c.Request.Context(), db, gameID, userID); err != nil {
+			if errors.Is(err, service.ErrGameNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found", "game_id": gameID})
+				return
+			}
+			respondGameEditError(c, err, "Failed to delete game")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Game deleted", "game_id": gameID})
+	}
+}
+
+// GetGameAuditLog returns every game create, edit and delete for a week
+func GetGameAuditLog(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		entries, err := service.GetGameAuditLog(db, weekID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get game audit log"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"week_id": weekID,
+			"entries": entries,
+		})
+	}
+}
+
+// respondGameEditError maps the errors shared by creating, updating and deleting games
+func respondGameEditError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, service.ErrWeekGamesLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": "Games can only be changed before the week is activated"})
+	case errors.Is(err, service.ErrExternalGameIDTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Another game in this season already has this external game ID"})
+	case errors.Is(err, service.ErrTeamAlreadyPlaying):
+		c.JSON(http.StatusConflict, gin.H{"error": "One of the teams already has a game this week"})
+	case errors.Is(err, service.ErrTeamMappingFailed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown team"})
+	case errors.Is(err, service.ErrTeamPlaysItself):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A team can't play itself"})
+	case errors.Is(err, service.ErrInvalidGameValue):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNoGameChanges):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to change"})
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+	}
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/api/middleware"
+	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/service"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+// requestLeagueID returns the league a request is for: the :league_id route param on league routes, otherwise
+// the optional ?league_id= query param.  Empty when neither is set
+func requestLeagueID(c *gin.Context) string {
+	if leagueID := c.Param("league_id"); leagueID != "" {
+		return leagueID
+	}
+	return c.Query("league_id")
+}
+
+// canManageLeague checks the logged in user is an admin or an owner or commissioner of the league.  The league
+// routes check this in RequireLeagueRole; global routes that take a ?league_id= use this
+func canManageLeague(db *sqlx.DB, c *gin.Context, leagueID string) (bool, error) {
+	if middleware.GetClaims(c).UserRole == "admin" {
+		return true, nil
+	}
+	role, err := service.GetLeagueRole(db, leagueID, middleware.GetUserID(c))
+	if err != nil {
+		return false, err
+	}
+	return role == service.LeagueRoleOwner || role == service.LeagueRoleCommissioner, nil
+}
+
+// canViewLeague checks the logged in user is an admin or a member of the league.  For global routes that take
+// a ?league_id=
+func canViewLeague(db *sqlx.DB, c *gin.Context, leagueID string) (bool, error) {
+	if middleware.GetClaims(c).UserRole == "admin" {
+		return true, nil
+	}
+	return service.IsLeagueMember(db, leagueID, middleware.GetUserID(c))
+}
+
+// requireLeagueViewer responds 403 and returns false unless the logged in user can see the league.  League routes
+// already check membership in RequireLeagueRole, so this only checks on global routes
+func requireLeagueViewer(db *sqlx.DB, c *gin.Context, leagueID string) bool {
+	if c.Param("league_id") != "" {
+		return true
+	}
+	canView, err := canViewLeague(db, c, leagueID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if !canView {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this league"})
+		return false
+	}
+	return true
+}
+
+// CreateLeague creates a new league.  The user creating it becomes its owner
+func CreateLeague(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		league, err := service.CreateLeague(db, req.Name, userID)
+		if err != nil {
+			respondLeagueError(c, err, "Failed to create league")
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"league": league})
+	}
+}
+
+// GetMyLeagues returns the leagues the logged in user is in, with their role in each
+func GetMyLeagues(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		leagues, err := service.GetUserLeagues(db, userID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"leagues": leagues})
+	}
+}
+
+// GetLeague returns a league and its members.  Members only (see RequireLeagueRole)
+func GetLeague(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+
+		league, err := service.GetLeague(db, leagueID)
+		if err != nil {
+			respondLeagueError(c, err, "Database error")
+			return
+		}
+		league.Role = middleware.GetLeagueRole(c)
+
+		members, err := service.GetLeagueMembers(db, leagueID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// full avatar URLs, same as season participants
+		for i := range members {
+			avatarURL := buildAvatarURL(members[i].AvatarURL)
+			members[i].AvatarURL = &avatarURL
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"league":  league,
+			"members": members,
+		})
+	}
+}
+
+// AddLeagueMember adds a user to a league.  Role defaults to member.
+// Users already in the league keep their role (use UpdateLeagueMemberRole to change it)
+func AddLeagueMember(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+
+		var req struct {
+			UserID string `json:"user_id" binding:"required"`
+			Role   string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if req.Role == "" {
+			req.Role = service.LeagueRoleMember
+		}
+
+		if err := service.AddLeagueMember(db, leagueID, req.UserID, req.Role); err != nil {
+			respondLeagueError(c, err, "Failed to add league member")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"league_id": leagueID,
+			"user_id":   req.UserID,
+		})
+	}
+}
+
+// UpdateLeagueMemberRole changes a member's league role.  A league always keeps at least one owner
+func UpdateLeagueMemberRole(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+		userID := c.Param("user_id")
+
+		var req struct {
+			Role string `json:"role" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if err := service.UpdateLeagueMemberRole(db, leagueID, userID, req.Role); err != nil {
+			respondLeagueError(c, err, "Failed to update league member")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"league_id": leagueID,
+			"user_id":   userID,
+			"role":      req.Role,
+		})
+	}
+}
+
+// RemoveLeagueMember takes a user out of a league.
+// Note: This doesn't remove them from the league's seasons or delete their picks
+func RemoveLeagueMember(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+		userID := c.Param("user_id")
+
+		if err := service.RemoveLeagueMember(db, leagueID, userID); err != nil {
+			respondLeagueError(c, err, "Failed to remove league member")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"removed": true,
+			"user_id": userID,
+		})
+	}
+}
+
+// GetLeagueSettings returns the league's own settings (null when it uses the global settings for everything) and
+// the settings new seasons in the league start with
+func GetLeagueSettings(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+
+		leagueSettings, err := service.GetLeagueSettings(db, leagueID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		effective, err := settings.GetForLeague(db, leagueID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"settings":  leagueSettings,
+			"effective": effective,
+		})
+	}
+}
+
+// UpdateLeagueSettings replaces a league's settings.  A null or missing field uses the global setting.
+// Only seasons created afterwards pick up the scoring and pick rules; the timezone applies to every season in the league
+func UpdateLeagueSettings(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		leagueID := c.Param("league_id")
+
+		var req struct {
+			PickCutoffMinutes          *int    `json:"pick_cutoff_minutes"`
+			AllowPickEdits             *bool   `json:"allow_pick_edits"`
+			PointsPerCorrectPick       *int    `json:"points_per_correct_pick"`
+			CompetitionTimezone        *string `json:"competition_timezone"`
+			AllowCommissionerOverrides *bool   `json:"allow_commissioner_overrides"`
+			AllowPicksAfterKickoff     *bool   `json:"allow_picks_after_kickoff"`
+			PickLockRule               *string `json:"pick_lock_rule"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if req.PickCutoffMinutes != nil && *req.PickCutoffMinutes < -1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pick_cutoff_minutes can't be negative (-1 turns the cutoff off)"})
+			return
+		}
+		if req.PointsPerCorrectPick != nil && *req.PointsPerCorrectPick < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "points_per_correct_pick can't be negative"})
+			return
+		}
+		if req.CompetitionTimezone != nil {
+			if _, err := time.LoadLocation(*req.CompetitionTimezone); err != nil || *req.CompetitionTimezone == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid competition_timezone"})
+				return
+			}
+		}
+		if req.PickLockRule != nil && !settings.IsValidPickLockRule(*req.PickLockRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pick_lock_rule.  Use per_game or sunday_1pm"})
+			return
+		}
+
+		updated, err := service.SetLeagueSettings(db, leagueID, models.LeagueSettings{
+			PickCutoffMinutes:          req.PickCutoffMinutes,
+			AllowPickEdits:             req.AllowPickEdits,
+			PointsPerCorrectPick:       req.PointsPerCorrectPick,
+			CompetitionTimezone:        req.CompetitionTimezone,
+			AllowCommissionerOverrides: req.AllowCommissionerOverrides,
+			AllowPicksAfterKickoff:     req.AllowPicksAfterKickoff,
+			PickLockRule:               req.PickLockRule,
+		})
+		if err != nil {
+			respondLeagueError(c, err, "Failed to update league settings")
+			return
+		}
+
+		effective, err := settings.GetForLeague(db, leagueID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"settings":  updated,
+			"effective": effective,
+		})
+	}
+}
+
+// respondLeagueError maps league service errors to responses.  Anything unexpected is a 500 with the failure message
+func respondLeagueError(c *gin.Context, err error, failure string) {
+	switch {
+	case errors.Is(err, service.ErrLeagueNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "League not found"})
+	case errors.Is(err, service.ErrLeagueMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this league"})
+	case errors.Is(err, service.ErrLeagueUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrLeagueNameRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "League name is required"})
+	case errors.Is(err, service.ErrInvalidLeagueRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of owner, commissioner or member"})
+	case errors.Is(err, service.ErrLastLeagueOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "A league needs at least one owner"})
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+	}
+}
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -40,18 +44,34 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		// get global settings (lockout times)
-		settings, err := settings.Get(db)
+		// get the season's settings (lockout times)
+		settings, err := settings.GetForWeek(db, weekID)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load global settings"})
 			return
 		}
 
+		// the week's season decides whether confidence values are needed
+		week, err := service.GetWeekWithYear(db, weekID)
+		if err != nil {
+			if errors.Is(err, service.ErrWeekNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found"})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		confidenceMode := week.ScoringMode == service.ScoringModeConfidence
+		survivorMode := week.SeasonType == service.SeasonTypeSurvivor
+
 		// parse request
 		type PickSubmission struct {
 			GameID         string  `json:"game_id" binding:"required"`
 			SelectedTeamID *string `json:"selected_team_id" binding:"required"`
+			Confidence     *int    `json:"confidence"` // only used in confidence seasons
+			TotalPick      *string `json:"total_pick"` // over or under, only used when the week has totals enabled
 		}
 
 		type SubmitPicksRequest struct {
@@ -84,6 +104,49 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 			seenGameIDs[pick.GameID] = true
 		}
 
+		// confidence values are ignored outside of confidence seasons
+		if !confidenceMode {
+			for i := range req.Picks {
+				req.Picks[i].Confidence = nil
+			}
+		}
+
+		// over/under picks are ignored unless the week has totals turned on
+		for i := range req.Picks {
+			if !week.TotalsEnabled {
+				req.Picks[i].TotalPick = nil
+				continue
+			}
+
+			totalPick := req.Picks[i].TotalPick
+			if totalPick != nil && *totalPick != service.TotalPickOver && *totalPick != service.TotalPickUnder {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Total pick must be over or under",
+					"game_id": req.Picks[i].GameID,
+				})
+				return
+			}
+		}
+
+		// survivor seasons take exactly one team per week, and only from users who are still alive
+		if survivorMode {
+			if len(req.Picks) != 1 || req.Picks[0].SelectedTeamID == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Survivor seasons take exactly one team per week"})
+				return
+			}
+
+			eliminated, err := service.IsUserEliminated(db, week.SeasonID, userID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+			if eliminated {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You have been eliminated from this survivor season"})
+				return
+			}
+		}
+
 		// start transaction
 		tx, err := db.Beginx()
 		if err != nil {
@@ -99,12 +162,13 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 			KickoffTime time.Time `db:"kickoff_time"`
 			HomeTeamID  string    `db:"home_team_id"`
 			AwayTeamID  string    `db:"away_team_id"`
+			Voided      bool      `db:"voided"`
 		}
 
 		var databaseGames []GameInfo
 
 		query := `
-			SELECT id, kickoff_time, home_team_id, away_team_id
+			SELECT id, kickoff_time, home_team_id, away_team_id, voided_at IS NOT NULL AS voided
 			FROM games
 			WHERE week_id = $1
 			FOR SHARE
@@ -118,9 +182,14 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 
 		// convert databaseGames slice to map of IDs for faster lookup
 		gameMap := make(map[string]GameInfo)
+		kickoffs := make(map[string]time.Time, len(databaseGames))
 		for _, game := range databaseGames {
 			gameMap[game.ID] = game
+			kickoffs[game.ID] = game.KickoffTime
 		}
+
+		// when each game's picks lock, from the cutoff minutes and the lock rule (none in testing mode)
+		deadlines := service.PickDeadlines(kickoffs, settings)
 		// Games are now loaded and we can begin validation
 
 		// Validate picks (advisory only)
@@ -134,29 +203,153 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 				return
 			}
 
-			// Check if pick is still allowed (skip if testing mode (-1))
-			if settings.PickCutoffMinutes != -1 {
-				// time math by claude... this stuff is hard
-				// all times are UTC
-				cutoff := game.KickoffTime.Add(-time.Duration(settings.PickCutoffMinutes) * time.Minute)
-				if time.Now().UTC().After(cutoff) {
+			// voided games don't count so there's nothing to pick
+			if game.Voided {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "This game has been voided",
+					"game_id": pick.GameID,
+				})
+				return
+			}
+
+			// Check if pick is still allowed (there's no deadline in testing mode (-1))
+			if deadline, ok := deadlines[pick.GameID]; ok && time.Now().UTC().After(deadline) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":    "This game's pick window has closed",
+					"game_id":  pick.GameID,
+					"locks_at": deadline,
+				})
+				return
+			}
+
+			// make sure the team is a valid choice - allow nil choice
+			if pick.SelectedTeamID != nil {
+				if *pick.SelectedTeamID != game.HomeTeamID && *pick.SelectedTeamID != game.AwayTeamID {
 					c.JSON(http.StatusBadRequest, gin.H{
-						"error":   "This game's pick window has closed",
+						"error":   "Selected team does not belong to this game",
 						"game_id": pick.GameID,
 					})
 					return
 				}
 			}
+		}
 
-			// make sure the team is a valid choice - allow nil choice
-			if pick.SelectedTeamID != nil {
-				if *pick.SelectedTeamID != game.HomeTeamID && *pick.SelectedTeamID != game.AwayTeamID {
+		// In confidence seasons every selected pick needs a confidence between 1 and the number of games,
+		// and no two games in the week can share a confidence (including picks already saved)
+		if confidenceMode {
+			type savedConfidence struct {
+				GameID     string `db:"game_id"`
+				Confidence *int   `db:"confidence"`
+			}
+
+			var saved []savedConfidence
+			err := tx.Select(&saved, `
+				SELECT game_id, confidence
+				FROM picks
+				WHERE user_id = $1 AND week_id = $2
+			`, userID, weekID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+
+			confidenceByGameID := make(map[string]int)
+			for _, p := range saved {
+				if p.Confidence != nil {
+					confidenceByGameID[p.GameID] = *p.Confidence
+				}
+			}
+
+			for _, pick := range req.Picks {
+				if pick.SelectedTeamID != nil && pick.Confidence == nil {
 					c.JSON(http.StatusBadRequest, gin.H{
-						"error":   "Selected team does not belong to this game",
+						"error":   "Every pick needs a confidence value",
 						"game_id": pick.GameID,
 					})
 					return
 				}
+
+				if pick.Confidence == nil {
+					delete(confidenceByGameID, pick.GameID)
+					continue
+				}
+
+				if *pick.Confidence < 1 || *pick.Confidence > len(databaseGames) {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":      fmt.Sprintf("Confidence must be between 1 and %d", len(databaseGames)),
+						"game_id":    pick.GameID,
+						"confidence": *pick.Confidence,
+					})
+					return
+				}
+				confidenceByGameID[pick.GameID] = *pick.Confidence
+			}
+
+			gameIDByConfidence := make(map[int]string)
+			for gameID, confidence := range confidenceByGameID {
+				if otherGameID, exists := gameIDByConfidence[confidence]; exists {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":      "Each game needs a unique confidence value",
+						"confidence": confidence,
+						"game_ids":   []string{otherGameID, gameID},
+					})
+					return
+				}
+				gameIDByConfidence[confidence] = gameID
+			}
+		}
+
+		// In survivor seasons a team can only be used once, and picking a new game this week replaces
+		// the previous pick as long as that one isn't locked or started
+		if survivorMode {
+			pick := req.Picks[0]
+
+			used, err := service.IsSurvivorTeamUsed(tx, week.SeasonID, weekID, userID, *pick.SelectedTeamID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+			if used {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "You already used this team in an earlier week",
+					"game_id": pick.GameID,
+				})
+				return
+			}
+
+			_, err = tx.Exec(`
+				DELETE FROM picks pk
+				USING games g
+				WHERE g.id = pk.game_id
+				AND pk.user_id = $1
+				AND pk.week_id = $2
+				AND pk.game_id <> $3
+				AND pk.user_locked_at IS NULL
+				AND ($4 = true OR now() < g.kickoff_time)
+				AND ($5 = true OR pk.selected_team_id IS NULL)
+			`, userID, weekID, pick.GameID, settings.AllowPicksAfterKickoff, settings.AllowPickEdits)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace survivor pick"})
+				return
+			}
+
+			var otherPicks int
+			err = tx.Get(&otherPicks, `
+				SELECT COUNT(*)
+				FROM picks
+				WHERE user_id = $1 AND week_id = $2 AND game_id <> $3
+			`, userID, weekID, pick.GameID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+			if otherPicks > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Your survivor pick for this week is locked, final or its game has started"})
+				return
 			}
 		}
 
@@ -176,10 +369,12 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 
 			// once again query by claude
 			// the ON CONFLICT user_id game_id is what makes sure that there's only user pick per game, and where the id will get skipped
-			// because DO UPDATE SET will still actually update the team id of the pick.  nothing else needs to be udpdated
+			// because DO UPDATE SET will still actually update the team id (plus confidence and total pick) of the pick.  nothing else needs to be udpdated
+			// when pick edits are turned off the first pick with a team is final.  Sending the same pick again is fine
+			// since the frontend resubmits every pick
 			query := `
-				INSERT INTO picks (id, user_id, game_id, week_id, selected_team_id)
-				SELECT $1, $2, $3, $4, $5
+				INSERT INTO picks (id, user_id, game_id, week_id, selected_team_id, confidence, total_pick)
+				SELECT $1, $2, $3, $4, $5, $7, $8::public.total_pick
 				FROM games g
 				WHERE g.id = $3
 				AND (
@@ -188,7 +383,9 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 				)
 				ON CONFLICT (user_id, game_id)
 				DO UPDATE
-				SET selected_team_id = EXCLUDED.selected_team_id
+				SET selected_team_id = EXCLUDED.selected_team_id,
+					confidence = EXCLUDED.confidence,
+					total_pick = EXCLUDED.total_pick
 				WHERE
 				picks.user_locked_at IS NULL
 				AND (
@@ -198,10 +395,19 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 					FROM games
 					WHERE id = picks.game_id
 					)
+				)
+				AND (
+					$9 = true
+					OR picks.selected_team_id IS NULL
+					OR (
+						picks.selected_team_id = EXCLUDED.selected_team_id
+						AND picks.confidence IS NOT DISTINCT FROM EXCLUDED.confidence
+						AND picks.total_pick IS NOT DISTINCT FROM EXCLUDED.total_pick
+					)
 				);
 			`
 
-			result, err := tx.Exec(query, pickID, userID, pick.GameID, weekID, pick.SelectedTeamID, settings.AllowPicksAfterKickoff)
+			result, err := tx.Exec(query, pickID, userID, pick.GameID, weekID, pick.SelectedTeamID, settings.AllowPicksAfterKickoff, pick.Confidence, pick.TotalPick, settings.AllowPickEdits)
 			if err != nil {
 				c.Error(err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save pick"})
@@ -216,8 +422,12 @@ func SubmitPicks(db *sqlx.DB) gin.HandlerFunc {
 			}
 
 			if rows == 0 {
+				message := "Pick is locked or game has started"
+				if !settings.AllowPickEdits {
+					message = "Pick is locked, game has started or picks can't be changed once made"
+				}
 				c.JSON(http.StatusConflict, gin.H{
-					"error":   "Pick is locked or game has started",
+					"error":   message,
 					"game_id": pick.GameID,
 				})
 				return
@@ -248,6 +458,31 @@ func LockWeekPicks(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// optional tiebreaker: predicted combined score of the last game of the week
+		var req struct {
+			TiebreakerTotal *int `json:"tiebreaker_total"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if req.TiebreakerTotal != nil && *req.TiebreakerTotal < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tiebreaker total can't be negative"})
+			return
+		}
+
+		// confidence seasons need a confidence value on every pick before locking, and totals weeks need an over/under
+		week, err := service.GetWeekWithYear(db, weekID)
+		if err != nil {
+			if errors.Is(err, service.ErrWeekNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found"})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
 		// Check if user is a participant in this season.
 		// Non-participants cannot lock picks (they shouldn't have any anyway).
 		isParticipant, err := service.IsUserSeasonParticipant(db, weekID, userID)
@@ -277,21 +512,26 @@ func LockWeekPicks(db *sqlx.DB) gin.HandlerFunc {
 			Incomplete int `db:"incomplete"` // total number of picks that don't have a team selected
 		}
 
-		// check pick status
+		// check pick status.  In confidence seasons a pick without a confidence value is incomplete,
+		// and in weeks with totals a pick without an over/under is incomplete
 		query := `
 			SELECT
 				COUNT(*) AS total,
 				COUNT(*) FILTER (WHERE user_locked_at IS NULL) AS unlocked,
 				COUNT(*) FILTER (
 					WHERE user_locked_at IS NULL
-					AND selected_team_id IS NULL
+					AND (
+						selected_team_id IS NULL
+						OR ($3 AND confidence IS NULL)
+						OR ($4 AND total_pick IS NULL)
+					)
 				) AS incomplete
 			FROM picks
 			WHERE user_id = $1
 			AND week_id = $2
 			`
 
-		err = tx.Get(&pickLockStatus, query, userID, weekID)
+		err = tx.Get(&pickLockStatus, query, userID, weekID, week.ScoringMode == service.ScoringModeConfidence, week.TotalsEnabled)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate picks"})
@@ -309,10 +549,35 @@ func LockWeekPicks(db *sqlx.DB) gin.HandlerFunc {
 		}
 
 		if pickLockStatus.Incomplete > 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "All picks must be selected before locking"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "All picks must be complete before locking"})
 			return
 		}
 
+		// Save the tiebreaker prediction.  Survivor seasons don't rank weeks so there's nothing to break
+		if req.TiebreakerTotal != nil && week.SeasonType != service.SeasonTypeSurvivor {
+			tiebreakerGame, err := service.GetTiebreakerGame(tx, weekID)
+			if errors.Is(err, service.ErrNoTiebreakerGame) {
+				c.JSON(http.StatusConflict, gin.H{"error": "This week has no games to use for a tiebreaker.  Lock your picks without a tiebreaker_total"})
+				return
+			}
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+
+			if !time.Now().UTC().Before(tiebreakerGame.KickoffTime) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "The tiebreaker game has already started"})
+				return
+			}
+
+			if err := service.SaveTiebreaker(tx, userID, weekID, tiebreakerGame.ID, *req.TiebreakerTotal); err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tiebreaker"})
+				return
+			}
+		}
+
 		// Actually lock the picks by setting user_locked_at
 		_, err = tx.Exec(`
 			UPDATE picks
@@ -336,7 +601,8 @@ func LockWeekPicks(db *sqlx.DB) gin.HandlerFunc {
 
 		// response
 		c.JSON(http.StatusOK, gin.H{
-			"locked_picks": pickLockStatus.Total,
+			"locked_picks":     pickLockStatus.Total,
+			"tiebreaker_total": req.TiebreakerTotal,
 		})
 	}
 }
@@ -368,7 +634,16 @@ func GetMyPicks(db *sqlx.DB) gin.HandlerFunc {
 			userPicks = []models.Pick{}
 		}
 
-		c.JSON(http.StatusOK, gin.H{"picks": userPicks})
+		// my tiebreaker prediction, if I've made one
+		var tiebreakerTotal *int
+		err = db.Get(&tiebreakerTotal, `SELECT predicted_total FROM public.week_tiebreakers WHERE week_id = $1 AND user_id = $2`, weekID, userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"picks": userPicks, "tiebreaker_total": tiebreakerTotal})
 	}
 }
 
@@ -415,7 +690,7 @@ func GetMyWeekPickSummary(db *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
-// returns a summary of user picks for a week
+// returns a summary of picks for each of the week's season participants
 func GetWeekPickSummary(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -448,13 +723,18 @@ func GetWeekPickSummary(db *sqlx.DB) gin.HandlerFunc {
                 p.id as user_id,
                 p.username,
                 COALESCE(COUNT(pk.id) FILTER (WHERE pk.selected_team_id IS NOT NULL), 0) as picks_submitted
-            FROM public.profiles p
-            LEFT JOIN public.picks pk ON pk.user_id = p.id AND pk.week_id = $1
+            FROM public.weeks w
+            JOIN public.season_participants sp ON sp.season_id = w.season_id
+            JOIN public.profiles p ON p.id = sp.user_id
+            LEFT JOIN public.season_roles sr ON sr.season_id = w.season_id AND sr.user_id = p.id
+            LEFT JOIN public.picks pk ON pk.user_id = p.id AND pk.week_id = w.id
+            WHERE w.id = $1
+                AND sr.role IS DISTINCT FROM $2
             GROUP BY p.id, p.username
             ORDER BY picks_submitted DESC, p.username ASC
         `
 
-		err = db.Select(&userPickSummary, query, weekID)
+		err = db.Select(&userPickSummary, query, weekID, service.SeasonRoleSpectator)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Error"})
@@ -474,7 +754,7 @@ func GetWeekPickSummary(db *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
-// returns all users with their locked picks for a week
+// returns the week's season participants with their locked picks
 func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		weekID := c.Param("week_id")
@@ -485,17 +765,27 @@ func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 
 		// Pick detail for a specific game
 		type PickDetail struct {
-			GameID         string `json:"game_id" db:"game_id"`
-			SelectedTeamID string `json:"selected_team_id" db:"selected_team_id"`
-			IsCorrect      *bool  `json:"is_correct" db:"is_correct"`
+			GameID         string  `json:"game_id" db:"game_id"`
+			SelectedTeamID *string `json:"selected_team_id" db:"selected_team_id"` // nil when a commissioner cleared the pick
+			IsCorrect      *bool   `json:"is_correct" db:"is_correct"`
+			Confidence     *int    `json:"confidence" db:"confidence"`
+			TotalPick      *string `json:"total_pick" db:"total_pick"`
+			TotalIsCorrect *bool   `json:"total_is_correct" db:"total_is_correct"`
+
+			// set when a commissioner entered the pick for the user
+			OverriddenBy         *string    `json:"overridden_by,omitempty"`
+			OverriddenByUsername *string    `json:"overridden_by_username,omitempty"`
+			OverriddenAt         *time.Time `json:"overridden_at,omitempty"`
+			OverrideReason       *string    `json:"override_reason,omitempty"`
 		}
 
 		// User with their picks and avatar
 		type UserWithPicks struct {
-			UserID    string       `json:"user_id"`
-			Username  string       `json:"username"`
-			AvatarURL *string      `json:"avatar_url"`
-			Picks     []PickDetail `json:"picks"`
+			UserID          string       `json:"user_id"`
+			Username        string       `json:"username"`
+			AvatarURL       *string      `json:"avatar_url"`
+			Picks           []PickDetail `json:"picks"`
+			TiebreakerTotal *int         `json:"tiebreaker_total"`
 		}
 
 		// Profile from db
@@ -511,34 +801,56 @@ func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 			GameID         *string `db:"game_id"`
 			SelectedTeamID *string `db:"selected_team_id"`
 			IsCorrect      *bool   `db:"is_correct"`
+			Confidence     *int    `db:"confidence"`
+			TotalPick      *string `db:"total_pick"`
+			TotalIsCorrect *bool   `db:"total_is_correct"`
+
+			OverriddenBy         *string    `db:"overridden_by"`
+			OverriddenByUsername *string    `db:"overridden_by_username"`
+			OverriddenAt         *time.Time `db:"overridden_at"`
+			OverrideReason       *string    `db:"override_reason"`
 		}
 
-		// Query 1: Get all users
+		// Query 1: Get the week's season participants (spectators don't pick)
 		var profiles []Profile
 		profilesQuery := `
-			SELECT id, username, avatar_url
-			FROM public.profiles
-			ORDER BY username
+			SELECT p.id, p.username, p.avatar_url
+			FROM public.weeks w
+			JOIN public.season_participants sp ON sp.season_id = w.season_id
+			JOIN public.profiles p ON p.id = sp.user_id
+			LEFT JOIN public.season_roles sr ON sr.season_id = w.season_id AND sr.user_id = p.id
+			WHERE w.id = $1
+				AND sr.role IS DISTINCT FROM $2
+			ORDER BY p.username
 		`
-		err := db.Select(&profiles, profilesQuery)
+		err := db.Select(&profiles, profilesQuery, weekID, service.SeasonRoleSpectator)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Error"})
 			return
 		}
 
-		// Query 2: Get picks that are "visible" - either explicitly locked OR game has started
+		// Query 2: Get picks that are "visible" - either explicitly locked OR game has started.
+		// Picks a commissioner cleared are included (without a team) so the override shows up
 		var lockedPicks []LockedPick
 		picksQuery := `
 			SELECT
 				p.user_id,
 				p.game_id,
 				p.selected_team_id,
-				p.is_correct
+				p.is_correct,
+				p.confidence,
+				p.total_pick,
+				p.total_is_correct,
+				p.overridden_by,
+				o.username AS overridden_by_username,
+				p.overridden_at,
+				p.override_reason
 			FROM public.picks p
 			JOIN public.games g ON g.id = p.game_id
+			LEFT JOIN public.profiles o ON o.id = p.overridden_by
 			WHERE p.week_id = $1
-				AND p.selected_team_id IS NOT NULL
+				AND (p.selected_team_id IS NOT NULL OR p.overridden_by IS NOT NULL)
 				AND (
 					p.user_locked_at IS NOT NULL
 					OR g.kickoff_time <= NOW()
@@ -552,14 +864,40 @@ func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Query 3: Get tiebreaker predictions.  These are only saved when picks are locked so they're always visible
+		type Tiebreaker struct {
+			UserID         string `db:"user_id"`
+			PredictedTotal int    `db:"predicted_total"`
+		}
+		var tiebreakers []Tiebreaker
+		err = db.Select(&tiebreakers, `SELECT user_id, predicted_total FROM public.week_tiebreakers WHERE week_id = $1`, weekID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database Error"})
+			return
+		}
+
+		tiebreakerByUser := make(map[string]int)
+		for _, t := range tiebreakers {
+			tiebreakerByUser[t.UserID] = t.PredictedTotal
+		}
+
 		// Build a map of user_id -> picks
 		picksByUser := make(map[string][]PickDetail)
 		for _, pick := range lockedPicks {
-			if pick.GameID != nil && pick.SelectedTeamID != nil {
+			if pick.GameID != nil {
 				picksByUser[pick.UserID] = append(picksByUser[pick.UserID], PickDetail{
 					GameID:         *pick.GameID,
-					SelectedTeamID: *pick.SelectedTeamID,
+					SelectedTeamID: pick.SelectedTeamID,
 					IsCorrect:      pick.IsCorrect,
+					Confidence:     pick.Confidence,
+					TotalPick:      pick.TotalPick,
+					TotalIsCorrect: pick.TotalIsCorrect,
+
+					OverriddenBy:         pick.OverriddenBy,
+					OverriddenByUsername: pick.OverriddenByUsername,
+					OverriddenAt:         pick.OverriddenAt,
+					OverrideReason:       pick.OverrideReason,
 				})
 			}
 		}
@@ -571,11 +909,16 @@ func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 			if picks == nil {
 				picks = []PickDetail{}
 			}
+			var tiebreakerTotal *int
+			if total, ok := tiebreakerByUser[profile.ID]; ok {
+				tiebreakerTotal = &total
+			}
 			users = append(users, UserWithPicks{
-				UserID:    profile.ID,
-				Username:  profile.Username,
-				AvatarURL: profile.AvatarURL,
-				Picks:     picks,
+				UserID:          profile.ID,
+				Username:        profile.Username,
+				AvatarURL:       profile.AvatarURL,
+				Picks:           picks,
+				TiebreakerTotal: tiebreakerTotal,
 			})
 		}
 
@@ -584,3 +927,50 @@ func GetWeekLockedPicks(db *sqlx.DB) gin.HandlerFunc {
 		})
 	}
 }
+
+// OverridePick lets a commissioner set or clear a user's pick (someone who texted their picks in) when
+// commissioner overrides are turned on in settings.  Send selected_team_id null to clear the pick
+func OverridePick(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actingUserID := middleware.GetUserID(c)
+
+		weekID := c.Param("week_id")
+		userID := c.Param("user_id")
+		if weekID == "" || userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID or user ID"})
+			return
+		}
+
+		var req service.PickOverride
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body.  game_id and reason are required"})
+			return
+		}
+
+		pick, err := service.OverridePick(c.Request.Context(), db, weekID, userID, req, actingUserID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrOverridesDisabled):
+				c.JSON(http.StatusForbidden, gin.H{"error": "Commissioner overrides are turned off in settings"})
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+			case errors.Is(err, service.ErrGameNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found in this week", "game_id": req.GameID})
+			case errors.Is(err, service.ErrWeekNotOverridable):
+				c.JSON(http.StatusConflict, gin.H{"error": "Picks can only be overridden while the week is active or played (before grading)"})
+			case errors.Is(err, service.ErrUserNotParticipant):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a participant in this season", "user_id": userID})
+			case errors.Is(err, service.ErrOverrideReasonMissing):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+			case errors.Is(err, service.ErrInvalidPickOverride):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "game_id": req.GameID})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to override pick"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, pick)
+	}
+}
@@ -5,6 +5,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,10 +17,10 @@ import (
 
 // StandingWithUser is used for returning standings with username
 type StandingWithUser struct {
-	UserID   string `json:"user_id" db:"user_id"`
-	Username string `json:"username" db:"username"`
-	Points   int    `json:"points" db:"points"`
-	Rank     int    `json:"rank" db:"rank"`
+	UserID   string  `json:"user_id" db:"user_id"`
+	Username string  `json:"username" db:"username"`
+	Points   float64 `json:"points" db:"points"`
+	Rank     int     `json:"rank" db:"rank"`
 }
 
 // GetWeekResults returns a week's results for all users
@@ -46,11 +47,12 @@ func GetWeekResults(db *sqlx.DB) gin.HandlerFunc {
 
 		// initially I just returned the user ID, and that didn't have usernames
 		type WeekResultWithUser struct {
-			ID       string `json:"id" db:"id"`
-			UserID   string `json:"user_id" db:"user_id"`
-			Points   int    `json:"points" db:"points"`
-			Rank     int    `json:"rank" db:"rank"`
-			Username string `json:"username" db:"username"`
+			ID             string  `json:"id" db:"id"`
+			UserID         string  `json:"user_id" db:"user_id"`
+			Points         float64 `json:"points" db:"points"`
+			Rank           int     `json:"rank" db:"rank"`
+			Username       string  `json:"username" db:"username"`
+			TiebreakerDiff *int    `json:"tiebreaker_diff" db:"tiebreaker_diff"`
 		}
 
 		// Query week results, filtered to only include season participants.
@@ -62,7 +64,8 @@ func GetWeekResults(db *sqlx.DB) gin.HandlerFunc {
 				wr.user_id,
 				wr.points,
 				wr.rank,
-				p.username
+				p.username,
+				wr.tiebreaker_diff
 			FROM public.week_results wr
 			JOIN public.profiles p ON p.id = wr.user_id
 			JOIN public.weeks w ON w.id = wr.week_id
@@ -93,8 +96,8 @@ func GetWeekResults(db *sqlx.DB) gin.HandlerFunc {
 }
 
 // WeekPointsByUserID returns how many points each user got for the given week
-func WeekPointsByUserID(results []models.WeekResult) map[string]int {
-	weekPointsByUserID := make(map[string]int)
+func WeekPointsByUserID(results []models.WeekResult) map[string]float64 {
+	weekPointsByUserID := make(map[string]float64)
 
 	for _, r := range results {
 		weekPointsByUserID[r.UserID] = r.Points
@@ -104,8 +107,8 @@ func WeekPointsByUserID(results []models.WeekResult) map[string]int {
 }
 
 // CumulativePointsByUserID returns cumulative points a user has
-func CumulativePointsByUserID(standing []models.SeasonStanding) map[string]int {
-	cumulativePointsByUserID := make(map[string]int)
+func CumulativePointsByUserID(standing []models.SeasonStanding) map[string]float64 {
+	cumulativePointsByUserID := make(map[string]float64)
 
 	for _, s := range standing {
 		cumulativePointsByUserID[s.UserID] = s.Points
@@ -253,11 +256,11 @@ func GetMySeasonPoints(db *sqlx.DB) gin.HandlerFunc {
 
 		// initially I just returned the user ID, and that didn't have usernames
 		type WeeksWithPoints struct {
-			WeekNumber  int `json:"week_number" db:"week_number"`
-			WeekPoints  int `json:"week_points" db:"week_points"`
-			WeekRank    int `json:"week_rank" db:"week_rank"`
-			TotalPoints int `json:"total_points" db:"total_points"`
-			LeagueRank  int `json:"league_rank" db:"league_rank"`
+			WeekNumber  int     `json:"week_number" db:"week_number"`
+			WeekPoints  float64 `json:"week_points" db:"week_points"`
+			WeekRank    int     `json:"week_rank" db:"week_rank"`
+			TotalPoints float64 `json:"total_points" db:"total_points"`
+			LeagueRank  int     `json:"league_rank" db:"league_rank"`
 		}
 
 		// query by ChatGPT
@@ -318,16 +321,19 @@ func GetWeekWinners(db *sqlx.DB) gin.HandlerFunc {
 		}
 
 		type FirstPlaceFinish struct {
-			WeekID       string  `db:"week_id"`
-			WeekNumber   int     `db:"week_number"`
-			Points       int     `db:"points"`
-			UserID       string  `db:"user_id"`
-			Username     string  `db:"username"`
-			AvatarURL    *string `db:"avatar_url"`
-			WinnersCount int     `db:"winners_count"`
+			WeekID         string  `db:"week_id"`
+			WeekNumber     int     `db:"week_number"`
+			Points         float64 `db:"points"`
+			UserID         string  `db:"user_id"`
+			Username       string  `db:"username"`
+			AvatarURL      *string `db:"avatar_url"`
+			WinnersCount   int     `db:"winners_count"`
+			TiebreakerDiff *int    `db:"tiebreaker_diff"`
+			TiedOnPoints   int     `db:"tied_on_points"`
 		}
 
 		// Query week winners, filtered to only include season participants.
+		// rank already includes the tiebreaker, tied_on_points is how many users had the winning points before it
 		var finishes []FirstPlaceFinish
 		query := `
 			SELECT
@@ -337,7 +343,15 @@ func GetWeekWinners(db *sqlx.DB) gin.HandlerFunc {
 				wr.user_id,
 				p.username,
 				p.avatar_url,
-				COUNT(*) OVER (PARTITION BY w.id) as winners_count
+				COUNT(*) OVER (PARTITION BY w.id) as winners_count,
+				wr.tiebreaker_diff,
+				(
+					SELECT COUNT(*)
+					FROM public.week_results wr2
+					JOIN public.season_participants sp2 ON sp2.season_id = $1 AND sp2.user_id = wr2.user_id
+					WHERE wr2.week_id = w.id
+					AND wr2.points = wr.points
+				) as tied_on_points
 			FROM public.weeks w
 			JOIN public.week_results wr ON wr.week_id = w.id AND wr.rank = 1
 			JOIN public.profiles p ON p.id = wr.user_id
@@ -355,16 +369,20 @@ func GetWeekWinners(db *sqlx.DB) gin.HandlerFunc {
 		}
 
 		type Winner struct {
-			UserID    string `json:"user_id"`
-			Username  string `json:"username"`
-			AvatarURL string `json:"avatar_url"`
+			UserID         string `json:"user_id"`
+			Username       string `json:"username"`
+			AvatarURL      string `json:"avatar_url"`
+			TiebreakerDiff *int   `json:"tiebreaker_diff"`
 		}
 		type WeekWinners struct {
 			WeekID     string   `json:"week_id"`
 			WeekNumber int      `json:"week_number"`
-			Points     int      `json:"points"`
+			Points     float64  `json:"points"`
 			Winners    []Winner `json:"winners"`
 			IsTie      bool     `json:"is_tie"`
+
+			// more than one user had the winning points and the tiebreaker decided it
+			DecidedByTiebreaker bool `json:"decided_by_tiebreaker"`
 		}
 
 		weekMap := make(map[string]*WeekWinners)
@@ -378,13 +396,16 @@ func GetWeekWinners(db *sqlx.DB) gin.HandlerFunc {
 					Points:     f.Points,
 					Winners:    []Winner{},
 					IsTie:      f.WinnersCount > 1,
+
+					DecidedByTiebreaker: f.TiedOnPoints > f.WinnersCount,
 				}
 				weekOrder = append(weekOrder, f.WeekID)
 			}
 			weekMap[f.WeekID].Winners = append(weekMap[f.WeekID].Winners, Winner{
-				UserID:    f.UserID,
-				Username:  f.Username,
-				AvatarURL: buildAvatarURL(f.AvatarURL),
+				UserID:         f.UserID,
+				Username:       f.Username,
+				AvatarURL:      buildAvatarURL(f.AvatarURL),
+				TiebreakerDiff: f.TiebreakerDiff,
 			})
 		}
 
@@ -420,16 +441,25 @@ func GetUserWinCounts(db *sqlx.DB) gin.HandlerFunc {
 			Username     string  `db:"username"`
 			AvatarURL    *string `db:"avatar_url"`
 			WinnersCount int     `db:"winners_count"`
+			TiedOnPoints int     `db:"tied_on_points"`
 		}
 
 		// Query first-place finishes, filtered to only include season participants.
+		// rank already includes the tiebreaker so a tiebreak win counts as an outright win
 		var finishes []FirstPlaceFinish
 		query := `
 			SELECT
 				wr.user_id,
 				p.username,
 				p.avatar_url,
-				COUNT(*) OVER (PARTITION BY w.id) as winners_count
+				COUNT(*) OVER (PARTITION BY w.id) as winners_count,
+				(
+					SELECT COUNT(*)
+					FROM public.week_results wr2
+					JOIN public.season_participants sp2 ON sp2.season_id = $1 AND sp2.user_id = wr2.user_id
+					WHERE wr2.week_id = w.id
+					AND wr2.points = wr.points
+				) as tied_on_points
 			FROM public.weeks w
 			JOIN public.week_results wr ON wr.week_id = w.id AND wr.rank = 1
 			JOIN public.profiles p ON p.id = wr.user_id
@@ -451,6 +481,8 @@ func GetUserWinCounts(db *sqlx.DB) gin.HandlerFunc {
 			AvatarURL string `json:"avatar_url"`
 			Wins      int    `json:"wins"` // outright wins
 			Ties      int    `json:"ties"` // tied for first
+
+			TiebreakerWins int `json:"tiebreaker_wins"` // outright wins that needed the tiebreaker (included in wins)
 		}
 
 		userMap := make(map[string]*UserWins)
@@ -464,6 +496,9 @@ func GetUserWinCounts(db *sqlx.DB) gin.HandlerFunc {
 			}
 			if f.WinnersCount == 1 {
 				userMap[f.UserID].Wins++
+				if f.TiedOnPoints > 1 {
+					userMap[f.UserID].TiebreakerWins++
+				}
 			} else {
 				userMap[f.UserID].Ties++
 			}
@@ -529,12 +564,12 @@ func GetMyCurrentSeasonStandings(db *sqlx.DB) gin.HandlerFunc {
 
 		// get the latest standings with username
 		var myCurrentStandings struct {
-			UserID     string `json:"user_id" db:"user_id"`
-			Username   string `json:"username" db:"username"`
-			Points     int    `json:"points" db:"points"`
-			Rank       *int   `json:"rank" db:"rank"` // Pointer to handle NULL
-			TotalUsers int    `json:"total_users" db:"total_users"`
-			SeasonID   string `json:"season_id" db:"season_id"`
+			UserID     string  `json:"user_id" db:"user_id"`
+			Username   string  `json:"username" db:"username"`
+			Points     float64 `json:"points" db:"points"`
+			Rank       *int    `json:"rank" db:"rank"` // Pointer to handle NULL
+			TotalUsers int     `json:"total_users" db:"total_users"`
+			SeasonID   string  `json:"season_id" db:"season_id"`
 		}
 
 		// Query to get user's current standings.
@@ -579,3 +614,36 @@ func GetMyCurrentSeasonStandings(db *sqlx.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"my_standings": myCurrentStandings})
 	}
 }
+
+// GetProvisionalResults returns "if the games ended now" pick results and a leaderboard for an active week,
+// using live scores.  Nothing is saved; the real results come when the week is scored
+func GetProvisionalResults(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		results, err := service.GetProvisionalResults(db, weekID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+			case errors.Is(err, service.ErrWeekNotLive):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Provisional results are only available while a week is active"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"week_id":     results.WeekID,
+			"status":      results.Status,
+			"provisional": true,
+			"standings":   results.Standings,
+		})
+	}
+}
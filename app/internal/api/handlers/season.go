@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,20 +13,45 @@ import (
 	"pawked.com/sendyourpicks/internal/id"
 	"pawked.com/sendyourpicks/internal/models"
 	"pawked.com/sendyourpicks/internal/service"
+	"pawked.com/sendyourpicks/internal/settings"
 )
 
-// creates a new season.  Takes the year number, number of weeks, and a bool for if it's postseason or not
+// creates a new season.  Takes the year number, number of weeks, and a bool for if it's postseason or not.
+// The season belongs to the route's league, or ?league_id= (the default league if neither is set).  On the
+// global route ?league_id= is only allowed for admins and the league's owners and commissioners
 func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		userID := middleware.GetUserID(c)
 
+		leagueID := requestLeagueID(c)
+		if leagueID == "" {
+			leagueID = service.DefaultLeagueID
+		} else if c.Param("league_id") == "" {
+			canManage, err := canManageLeague(db, c, leagueID)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+			if !canManage {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the league's owners and commissioners can create seasons in it"})
+				return
+			}
+		}
+
 		// parse request body
 		var req struct {
 			Year           int      `json:"year"`
 			NumberOfWeeks  int      `json:"number_of_weeks"`
 			IsPostseason   bool     `json:"is_postseason"`
 			ParticipantIDs []string `json:"participant_ids"` // optional: user IDs to add as initial participants
+			ScoringMode    string   `json:"scoring_mode"`    // optional: standard (default) or confidence
+			SeasonType     string   `json:"season_type"`     // optional: pickem (default) or survivor
+			PushPolicy     string   `json:"push_policy"`     // optional: loss (default), win, half or void
+
+			// optional, survivor seasons only: grade against the spread (default) or straight-up
+			SurvivorUseSpread *bool `json:"survivor_use_spread"`
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -49,6 +75,47 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// default to standard scoring
+		if req.ScoringMode == "" {
+			req.ScoringMode = service.ScoringModeStandard
+		}
+
+		if req.ScoringMode != service.ScoringModeStandard && req.ScoringMode != service.ScoringModeConfidence {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scoring mode"})
+			return
+		}
+
+		// default to a regular pick'em season
+		if req.SeasonType == "" {
+			req.SeasonType = service.SeasonTypePickem
+		}
+
+		if req.SeasonType != service.SeasonTypePickem && req.SeasonType != service.SeasonTypeSurvivor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid season type"})
+			return
+		}
+
+		// survivor picks are one team per week so there's nothing to rank by confidence
+		if req.SeasonType == service.SeasonTypeSurvivor && req.ScoringMode == service.ScoringModeConfidence {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "survivor seasons can't use confidence scoring"})
+			return
+		}
+
+		// default to a push counting as a loss
+		if req.PushPolicy == "" {
+			req.PushPolicy = service.PushPolicyLoss
+		}
+
+		if !service.IsValidPushPolicy(req.PushPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid push policy"})
+			return
+		}
+
+		survivorUseSpread := true
+		if req.SurvivorUseSpread != nil {
+			survivorUseSpread = *req.SurvivorUseSpread
+		}
+
 		// Start transaction
 		tx, err := db.Beginx()
 		if err != nil {
@@ -58,14 +125,34 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 		}
 		defer tx.Rollback()
 
-		// Check if season already exists
+		if _, err := service.GetLeague(tx, leagueID); err != nil {
+			respondLeagueError(c, err, "Database error")
+			return
+		}
+
+		// participants have to be in the league already; the league's owners and commissioners add people to it
+		nonMembers, err := service.LeagueNonMembers(tx, leagueID, req.ParticipantIDs)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if len(nonMembers) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       "Participants must already be members of the league",
+				"not_members": nonMembers,
+			})
+			return
+		}
+
+		// Check if season already exists in this league
 		var seasonExists bool
 		err = tx.QueryRow(
 			`
 			SELECT EXISTS (
-				SELECT 1 FROM public.seasons WHERE year = $1 AND is_postseason = $2
+				SELECT 1 FROM public.seasons WHERE league_id = $1 AND year = $2 AND is_postseason = $3 AND season_type = $4
 			)
-			`, req.Year, req.IsPostseason).Scan(&seasonExists)
+			`, leagueID, req.Year, req.IsPostseason, req.SeasonType).Scan(&seasonExists)
 
 		if err != nil {
 			c.Error(err)
@@ -89,14 +176,19 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 		// Insert new season
 		_, err = tx.Exec(
 			`
-			INSERT INTO public.seasons (id, year, number_of_weeks, is_postseason, created_by)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO public.seasons (id, year, number_of_weeks, is_postseason, created_by, scoring_mode, season_type, survivor_use_spread, push_policy, league_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 			`,
 			seasonID,
 			req.Year,
 			req.NumberOfWeeks,
 			req.IsPostseason,
 			userID,
+			req.ScoringMode,
+			req.SeasonType,
+			survivorUseSpread,
+			req.PushPolicy,
+			leagueID,
 		)
 
 		// error inserting into database
@@ -106,6 +198,20 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// whoever creates the season owns it
+		if err := service.AddSeasonRole(tx, seasonID, userID, service.SeasonRoleOwner, nil); err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create season"})
+			return
+		}
+
+		// the season keeps its league's current scoring and pick rules even if the league or global settings change later
+		if err := settings.SnapshotForSeason(tx, seasonID); err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save season settings"})
+			return
+		}
+
 		// Insert initial participants if provided.
 		// Done within the same transaction so season + participants are atomic.
 		participantCount := 0
@@ -119,6 +225,11 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add participant"})
 				return
 			}
+			if err := service.AddSeasonRole(tx, seasonID, participantID, service.SeasonRoleParticipant, nil); err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add participant"})
+				return
+			}
 			participantCount++
 		}
 
@@ -131,14 +242,18 @@ func NewSeason(db *sqlx.DB) gin.HandlerFunc {
 		// everything worked
 		c.JSON(http.StatusCreated, gin.H{
 			"id":                seasonID,
+			"league_id":         leagueID,
 			"year":              req.Year,
+			"scoring_mode":      req.ScoringMode,
+			"season_type":       req.SeasonType,
+			"push_policy":       req.PushPolicy,
 			"participant_count": participantCount, // how many participants were added
 		})
 	}
 
 }
 
-// Marks a season as active.  Fails if another season is already active
+// Marks a season as active.  Fails if another season of the same type is already active in the league
 func ActivateSeason(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
@@ -153,22 +268,26 @@ func ActivateSeason(db *sqlx.DB) gin.HandlerFunc {
 		}
 		defer tx.Rollback()
 
-		// First, verify the season exists
-		var seasonExists bool
-		err = tx.Get(&seasonExists, `SELECT EXISTS(SELECT 1 FROM public.seasons WHERE id = $1)`, seasonID)
+		// First, verify the season exists and get its type and league
+		var season struct {
+			SeasonType string `db:"season_type"`
+			LeagueID   string `db:"league_id"`
+		}
+		err = tx.Get(&season, `SELECT season_type, league_id FROM public.seasons WHERE id = $1`, seasonID)
 		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+				return
+			}
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 			return
 		}
-		if !seasonExists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
-			return
-		}
 
-		// Check if another season is already active
+		// Check if another season of the same type is already active in the league.  A survivor season can run
+		// alongside a pick'em season, and other leagues have their own active seasons
 		var activeSeasonID string
-		err = tx.Get(&activeSeasonID, `SELECT id FROM public.seasons WHERE is_active = true LIMIT 1`)
+		err = tx.Get(&activeSeasonID, `SELECT id FROM public.seasons WHERE is_active = true AND league_id = $1 AND season_type = $2 LIMIT 1`, season.LeagueID, season.SeasonType)
 		// we can ignore ErrNoRows because that is what we actually want
 		if err != nil && err != sql.ErrNoRows {
 			c.Error(err)
@@ -307,13 +426,87 @@ func UpdateSeasonWeeks(db *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
-// GetActiveSeason returns the currently active season
+// UpdateSeasonPushPolicy changes how pushes are scored for a season.
+// Only allowed until the first week's picks are graded so every week in a season is scored the same way.
+func UpdateSeasonPushPolicy(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+
+		var req struct {
+			PushPolicy string `json:"push_policy"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if !service.IsValidPushPolicy(req.PushPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Push policy must be one of loss, win, half or void"})
+			return
+		}
+
+		// Make sure the season exists
+		seasonExists, err := service.SeasonExists(db, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !seasonExists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
+
+		// Don't allow a change once any picks have been graded
+		var gradedWeekCount int
+		err = db.Get(&gradedWeekCount, `
+			SELECT COUNT(*)
+			FROM public.weeks
+			WHERE season_id = $1
+			AND status IN ('picks_results_calculated', 'scored', 'final')
+		`, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if gradedWeekCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot change the push policy after picks have been graded"})
+			return
+		}
+
+		_, err = db.Exec(`UPDATE public.seasons SET push_policy = $1 WHERE id = $2`, req.PushPolicy, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update season"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"season_id":   seasonID,
+			"push_policy": req.PushPolicy,
+		})
+	}
+}
+
+// GetActiveSeason returns the currently active season in the route's league, or ?league_id= (default league if neither).
+// Defaults to the pick'em season, pass ?type=survivor for the survivor season.  Only the league's members can see it
 func GetActiveSeason(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var season models.Season
 
-		query := `SELECT * FROM public.seasons WHERE is_active = true LIMIT 1`
-		err := db.Get(&season, query)
+		seasonType := c.DefaultQuery("type", service.SeasonTypePickem)
+
+		leagueID := requestLeagueID(c)
+		if leagueID == "" {
+			leagueID = service.DefaultLeagueID
+		}
+		if !requireLeagueViewer(db, c, leagueID) {
+			return
+		}
+
+		query := `SELECT * FROM public.seasons WHERE is_active = true AND league_id = $1 AND season_type = $2 LIMIT 1`
+		err := db.Get(&season, query, leagueID, seasonType)
 
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -328,22 +521,38 @@ func GetActiveSeason(db *sqlx.DB) gin.HandlerFunc {
 		// set context to include season id and year
 		c.JSON(http.StatusOK, gin.H{
 			"id":            season.ID,
+			"league_id":     season.LeagueID,
 			"year":          season.Year,
 			"is_postseason": season.IsPostseason,
+			"scoring_mode":  season.ScoringMode,
+			"season_type":   season.SeasonType,
+			"push_policy":   season.PushPolicy,
 		})
 	}
 }
 
-// returns all seasons
+// returns all the seasons the user can see, or only the seasons in the route's league (or ?league_id=).
+// Admins see every league's seasons; everyone else sees their leagues' seasons and seasons they have a role in
 func GetAllSeasons(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		var seasons []models.Season
 
-		// build the query first
-		query := `SELECT id, year, is_active, number_of_weeks, is_postseason FROM public.seasons ORDER BY year`
+		// build the query first.  An empty league id matches every league
+		query := `
+			SELECT id, league_id, year, is_active, number_of_weeks, is_postseason, scoring_mode, season_type, survivor_use_spread, push_policy
+			FROM public.seasons
+			WHERE ($1 = '' OR league_id = $1)
+			AND (
+				$2
+				OR league_id IN (SELECT league_id FROM public.league_members WHERE user_id = $3)
+				OR id IN (SELECT season_id FROM public.season_roles WHERE user_id = $3)
+			)
+			ORDER BY year, season_type
+		`
 
-		err := db.Select(&seasons, query) // select for multiple rows, Get for single row
+		isAdmin := middleware.GetClaims(c).UserRole == "admin"
+		err := db.Select(&seasons, query, requestLeagueID(c), isAdmin, middleware.GetUserID(c)) // select for multiple rows, Get for single row
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -368,17 +577,23 @@ func GetSeason(db *sqlx.DB) gin.HandlerFunc {
 
 		type SeasonWithWeeks struct {
 			ID            string        `json:"id" db:"id"`
+			LeagueID      string        `json:"league_id" db:"league_id"`
 			Year          int           `json:"year" db:"year"`
 			IsActive      bool          `json:"is_active" db:"is_active"`
 			NumberOfWeeks int           `json:"number_of_weeks" db:"number_of_weeks"`
 			IsPostseason  bool          `json:"is_postseason" db:"is_postseason"`
+			ScoringMode   string        `json:"scoring_mode" db:"scoring_mode"`
+			SeasonType    string        `json:"season_type" db:"season_type"`
+			PushPolicy    string        `json:"push_policy" db:"push_policy"`
 			Weeks         []models.Week `json:"weeks"`
+
+			SurvivorUseSpread bool `json:"survivor_use_spread" db:"survivor_use_spread"`
 		}
 
 		var season SeasonWithWeeks
 
 		// Get season
-		seasonQuery := `SELECT id, year, is_active, number_of_weeks, is_postseason FROM public.seasons WHERE id = $1`
+		seasonQuery := `SELECT id, league_id, year, is_active, number_of_weeks, is_postseason, scoring_mode, season_type, survivor_use_spread, push_policy FROM public.seasons WHERE id = $1`
 		err := db.Get(&season, seasonQuery, seasonID)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -487,7 +702,8 @@ func GetSeasonParticipants(db *sqlx.DB) gin.HandlerFunc {
 
 // AddSeasonParticipants adds one or more users to a season.
 // Accepts an array of user IDs in the request body.
-// Skips users who are already participants.
+// Skips users who are already participants.  Every user has to be a member of the season's league already,
+// and users without a season role get the participant role
 func AddSeasonParticipants(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		seasonID := c.Param("season_id")
@@ -507,14 +723,29 @@ func AddSeasonParticipants(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		seasonExists, err := service.SeasonExists(db, seasonID)
+		var leagueID string
+		err := db.Get(&leagueID, `SELECT league_id FROM public.seasons WHERE id = $1`, seasonID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 			return
 		}
-		if !seasonExists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+
+		nonMembers, err := service.LeagueNonMembers(db, leagueID, req.UserIDs)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if len(nonMembers) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       "Participants must already be members of the league",
+				"not_members": nonMembers,
+			})
 			return
 		}
 
@@ -548,6 +779,12 @@ func AddSeasonParticipants(db *sqlx.DB) gin.HandlerFunc {
 			if rowsAffected > 0 {
 				addedCount++
 			}
+
+			if err := service.AddSeasonRole(tx, seasonID, userID, service.SeasonRoleParticipant, nil); err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add participant"})
+				return
+			}
 		}
 
 		// commit the transaction
@@ -565,7 +802,7 @@ func AddSeasonParticipants(db *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
-// RemoveSeasonParticipant removes a single user from a season.
+// RemoveSeasonParticipant removes a single user from a season, along with their participant role.
 // Returns 404 if the user wasn't a participant.
 // Note: This doesn't delete their picks or standings
 func RemoveSeasonParticipant(db *sqlx.DB) gin.HandlerFunc {
@@ -603,6 +840,256 @@ func RemoveSeasonParticipant(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// owners, co-commissioners and spectators keep their role
+		_, err = db.Exec(`
+			DELETE FROM public.season_roles
+			WHERE season_id = $1 AND user_id = $2 AND role = $3
+		`, seasonID, userID, service.SeasonRoleParticipant)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove participant role"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"removed": true,
+			"user_id": userID,
+		})
+	}
+}
+
+// GetSeasonSettings returns the scoring and pick rules a season plays by
+func GetSeasonSettings(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+
+		var seasonSettings models.SeasonSettings
+		err := db.Get(&seasonSettings, `SELECT * FROM public.season_settings WHERE season_id = $1`, seasonID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season settings not found"})
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"settings": seasonSettings})
+	}
+}
+
+// UpdateSeasonSettings changes a season's scoring and pick rules.
+// Only allowed until the season's first week is activated so every week plays by the same rules.
+func UpdateSeasonSettings(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+
+		var req struct {
+			PickCutoffMinutes          int    `json:"pick_cutoff_minutes"`
+			AllowPickEdits             bool   `json:"allow_pick_edits"`
+			PointsPerCorrectPick       int    `json:"points_per_correct_pick"`
+			AllowCommissionerOverrides bool   `json:"allow_commissioner_overrides"`
+			AllowPicksAfterKickoff     bool   `json:"allow_picks_after_kickoff"`
+			PickLockRule               string `json:"pick_lock_rule" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if req.PickCutoffMinutes < -1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pick_cutoff_minutes can't be negative (-1 turns the cutoff off)"})
+			return
+		}
+		if req.PointsPerCorrectPick < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "points_per_correct_pick can't be negative"})
+			return
+		}
+		if !settings.IsValidPickLockRule(req.PickLockRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pick_lock_rule.  Use per_game or sunday_1pm"})
+			return
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		defer tx.Rollback()
+
+		// lock the season so a week can't be activated while the rules change
+		var lockedID string
+		err = tx.Get(&lockedID, `SELECT id FROM public.seasons WHERE id = $1 FOR UPDATE`, seasonID)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// Don't allow a change once any week has been activated
+		var activatedWeekCount int
+		err = tx.Get(&activatedWeekCount, `
+			SELECT COUNT(*)
+			FROM public.weeks
+			WHERE season_id = $1
+			AND status IN ('active', 'played', 'picks_results_calculated', 'scored', 'final')
+		`, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if activatedWeekCount > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot change season settings after the first week is activated"})
+			return
+		}
+
+		// seasons created before season settings existed get their row here
+		var updated models.SeasonSettings
+		err = tx.Get(&updated, `
+			INSERT INTO public.season_settings (
+				season_id, pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick,
+				allow_commissioner_overrides, allow_picks_after_kickoff, pick_lock_rule
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (season_id)
+			DO UPDATE
+			SET pick_cutoff_minutes = EXCLUDED.pick_cutoff_minutes,
+				allow_pick_edits = EXCLUDED.allow_pick_edits,
+				points_per_correct_pick = EXCLUDED.points_per_correct_pick,
+				allow_commissioner_overrides = EXCLUDED.allow_commissioner_overrides,
+				allow_picks_after_kickoff = EXCLUDED.allow_picks_after_kickoff,
+				pick_lock_rule = EXCLUDED.pick_lock_rule
+			RETURNING *
+		`, seasonID, req.PickCutoffMinutes, req.AllowPickEdits, req.PointsPerCorrectPick, req.AllowCommissionerOverrides, req.AllowPicksAfterKickoff, req.PickLockRule)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update season settings"})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit update"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"settings": updated})
+	}
+}
+
+// GetMySeasonRole returns the logged in user's role in a season ("" when they don't have one)
+func GetMySeasonRole(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+		userID := middleware.GetUserID(c)
+
+		role, err := service.GetSeasonRole(db, seasonID, userID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"season_id": seasonID,
+			"role":      role,
+		})
+	}
+}
+
+// GetSeasonRoles returns everyone with a role in a season
+func GetSeasonRoles(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+
+		seasonExists, err := service.SeasonExists(db, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !seasonExists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			return
+		}
+
+		roles, err := service.GetSeasonRoles(db, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"roles": roles})
+	}
+}
+
+// SetSeasonRole assigns a user's role in a season (owner, co_commissioner, participant or spectator).
+// Note: This doesn't add or remove them as a season participant
+func SetSeasonRole(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+		userID := c.Param("user_id")
+
+		var req struct {
+			Role string `json:"role" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		seasonRole, err := service.SetSeasonRole(db, seasonID, userID, req.Role, middleware.GetUserID(c))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrSeasonNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			case errors.Is(err, service.ErrSeasonRoleUserNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			case errors.Is(err, service.ErrInvalidSeasonRole):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be one of owner, co_commissioner, participant or spectator"})
+			case errors.Is(err, service.ErrLastSeasonOwner):
+				c.JSON(http.StatusConflict, gin.H{"error": "A season needs at least one owner"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign season role"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"role": seasonRole})
+	}
+}
+
+// RemoveSeasonRole takes away a user's role in a season.
+// Returns 404 if the user didn't have one, and 409 for the season's last owner
+func RemoveSeasonRole(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+		userID := c.Param("user_id")
+
+		if err := service.RemoveSeasonRole(db, seasonID, userID); err != nil {
+			switch {
+			case errors.Is(err, service.ErrSeasonNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+			case errors.Is(err, service.ErrSeasonRoleNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "User has no role in this season"})
+			case errors.Is(err, service.ErrLastSeasonOwner):
+				c.JSON(http.StatusConflict, gin.H{"error": "A season needs at least one owner"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove season role"})
+			}
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"removed": true,
 			"user_id": userID,
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/settings"
 )
 
 // returns all of the current global settings
@@ -25,6 +26,7 @@ func GetGlobalSettings(db *sqlx.DB) gin.HandlerFunc {
 				allow_commissioner_overrides,
 				allow_picks_after_kickoff,
 				debug_mode,
+				pick_lock_rule,
 				created_at,
 				updated_at
 			FROM public.settings
@@ -57,6 +59,7 @@ func UpdateGlobalSettings(db *sqlx.DB) gin.HandlerFunc {
 			PointsPerCorrectPick       int    `json:"points_per_correct_pick"`
 			AllowCommissionerOverrides bool   `json:"allow_commissioner_overrides"`
 			CompetitionTimezone        string `json:"competition_timezone"`
+			PickLockRule               string `json:"pick_lock_rule"` // optional, keeps the current rule when empty
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -78,6 +81,13 @@ func UpdateGlobalSettings(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		if req.PickLockRule != "" && !settings.IsValidPickLockRule(req.PickLockRule) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid pick_lock_rule.  Use per_game or sunday_1pm",
+			})
+			return
+		}
+
 		// set up transaction
 		tx, err := db.Beginx()
 		if err != nil {
@@ -87,17 +97,18 @@ func UpdateGlobalSettings(db *sqlx.DB) gin.HandlerFunc {
 		}
 		defer tx.Rollback()
 
-		var settings models.Settings
+		var updated models.Settings
 
 		// build the query first
 		query := `
             UPDATE public.settings
-            SET pick_cutoff_minutes = $2, allow_pick_edits = $3, points_per_correct_pick = $4, allow_commissioner_overrides = $5, competition_timezone = $6
+            SET pick_cutoff_minutes = $2, allow_pick_edits = $3, points_per_correct_pick = $4, allow_commissioner_overrides = $5, competition_timezone = $6,
+                pick_lock_rule = COALESCE(NULLIF($7, ''), pick_lock_rule)
             WHERE id = $1
             RETURNING *
 		`
 
-		err = tx.Get(&settings, query, req.Id, req.PickCutoffMinutes, req.AllowPickEdits, req.PointsPerCorrectPick, req.AllowCommissionerOverrides, req.CompetitionTimezone)
+		err = tx.Get(&updated, query, req.Id, req.PickCutoffMinutes, req.AllowPickEdits, req.PointsPerCorrectPick, req.AllowCommissionerOverrides, req.CompetitionTimezone, req.PickLockRule)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -114,7 +125,7 @@ func UpdateGlobalSettings(db *sqlx.DB) gin.HandlerFunc {
 
 		// return the updated settings
 		c.JSON(http.StatusOK, gin.H{
-			"settings": settings,
+			"settings": updated,
 		})
 	}
 }
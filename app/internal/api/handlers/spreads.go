@@ -1,24 +1,29 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
-	"pawked.com/sendyourpicks/internal/logger"
-	"pawked.com/sendyourpicks/internal/models"
 	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/logger"
 	"pawked.com/sendyourpicks/internal/service"
 )
 
-// timesAreClose checks if two times are within the tolerance window
-// This handles timezone differences and slight schedule adjustments
-func timesAreClose(t1, t2 time.Time, tolerance time.Duration) bool {
-	diff := math.Abs(float64(t1.Sub(t2)))
-	return diff <= float64(tolerance)
+// providerErrorStatus picks the response status for a failed provider request: 503 when the provider is down
+// or rate limiting us (try again later), 502 when it rejected the request (fix the config)
+func providerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, external.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, external.ErrProviderBadRequest):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
 }
 
 // AutoImportSpreads automatically fetches and sets spreads from the Odds API
@@ -34,19 +39,31 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 		// specify preferred bookmaker in query param.  Maybe this shoul be set in global settings or even ENV?
 		bookmaker := c.DefaultQuery("bookmaker", "draftkings")
 
+		// bookmaker uses one bookmaker's line, median and mean combine every bookmaker's line
+		method := c.DefaultQuery("method", external.SpreadMethodBookmaker)
+		if !external.IsValidSpreadMethod(method) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Invalid method.  Must be bookmaker, median or mean",
+				"method": method,
+			})
+			return
+		}
+
 		logger.Info(
 			"auto-importing spreads from odds API",
 			"week_id", weekID,
 			"bookmaker", bookmaker,
+			"method", method,
 		)
 
-		// Check week exists and get status
-		weekStatus, err := service.GetWeekStatus(db, weekID)
+		// Check week exists and get status (and whether totals are needed)
+		week, err := service.GetWeekWithYear(db, weekID)
 		if err != nil {
 			c.Error(err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 			return
 		}
+		weekStatus := week.Status
 
 		// Check if week is in games_imported or spreads_set status
 		if weekStatus != "games_imported" && weekStatus != "spreads_set" {
@@ -57,24 +74,8 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Get all games for this week.  I should really make a getGames function in my services
-		var games []models.Game
-		err = db.Select(&games, `
-			SELECT
-				g.id,
-				g.home_team_id,
-				g.away_team_id,
-				g.home_spread,
-				g.kickoff_time,
-				ht.abbreviation as home_team_abbr,
-				at.abbreviation as away_team_abbr,
-				ht.name as home_team_name,
-				at.name as away_team_name
-			FROM games g
-			JOIN teams ht ON g.home_team_id = ht.id
-			JOIN teams at ON g.away_team_id = at.id
-			WHERE g.week_id = $1
-		`, weekID)
+		// Get all games for this week
+		games, err := service.GetSpreadImportGames(db, weekID)
 		if err != nil {
 			logger.Error(
 				"failed to fetch games for week",
@@ -111,14 +112,37 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Odds API team names go through team_aliases
+		teamAliases, err := service.GetTeamAliasMap(db, external.ProviderOdds)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// file any recorded/replayed odds under this week
+		oddsClient = oddsClient.ForWeek(week.Year, week.Number, week.IsPostseason).WithTeamAliases(teamAliases)
+
 		// Fetch spreads from Odds API
-		spreads, err := oddsClient.FetchSpreads(c.Request.Context(), bookmaker)
+		var spreads []external.SpreadInfo
+		if method == external.SpreadMethodBookmaker {
+			spreads, err = oddsClient.FetchSpreads(c.Request.Context(), bookmaker, week.TotalsEnabled)
+		} else {
+			spreads, err = oddsClient.FetchConsensusSpreads(c.Request.Context(), method, week.TotalsEnabled)
+		}
+		if errors.Is(err, external.ErrOddsQuotaLow) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":      "Odds API quota is too low to fetch spreads.  Set spreads manually or wait for the quota to reset",
+				"odds_quota": external.CurrentOddsQuota(),
+			})
+			return
+		}
 		if err != nil {
 			logger.Error(
 				"failed to fetch spreads from odds API",
 				"error", err,
 			)
-			c.JSON(http.StatusInternalServerError, gin.H{
+			c.JSON(providerErrorStatus(err), gin.H{
 				"error":   "Failed to fetch spreads from Odds API",
 				"details": err.Error(),
 			})
@@ -130,66 +154,53 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 			"spread_count", len(spreads),
 		)
 
-		// Match games with spreads using team matchup AND kickoff time
-		// Tolerance of 6 hours handles timezone differences and slight schedule adjustments
-		const kickoffTolerance = 6 * time.Hour
-
 		type GameUpdate struct {
 			GameID     string
 			HomeSpread float64
+			Total      *float64
+			Lines      []external.BookmakerLine
 			Matched    bool
 		}
 
+		// what went into each game's line, for the response
+		type GameSpreadSource struct {
+			GameID     string   `json:"game_id"`
+			Matchup    string   `json:"matchup"`
+			HomeSpread float64  `json:"home_spread"`
+			Total      *float64 `json:"total"`
+			Bookmaker  string   `json:"bookmaker"`
+			BookCount  int      `json:"book_count"`
+			SpreadMin  float64  `json:"spread_min"`
+			SpreadMax  float64  `json:"spread_max"`
+		}
+		sources := make([]GameSpreadSource, 0, len(games))
+
 		updates := make([]GameUpdate, 0, len(games))
 		matchedCount := 0
 		unmatchedGames := []string{}
 
 		// range over all the games and attach the spreads
 		for _, game := range games {
-			var matchedSpread *external.SpreadInfo
-
-			// Look for matching spread by team matchup AND kickoff time
-			for i := range spreads {
-				spread := &spreads[i]
-
-				// Check if teams match
-				teamsMatch := (spread.HomeTeamAbbr == game.HomeTeamAbbr &&
-					spread.AwayTeamAbbr == game.AwayTeamAbbr)
-
-				if !teamsMatch {
-					continue
-				}
-
-				// Check if kickoff times are close
-				if timesAreClose(spread.CommenceTime, game.KickoffTime, kickoffTolerance) {
-					matchedSpread = spread
-					logger.Info(
-						"matched game with spread",
-						"game_id", game.ID,
-						"matchup", fmt.Sprintf("%s @ %s", game.AwayTeamAbbr, game.HomeTeamAbbr),
-						"spread", spread.HomeSpread,
-						"db_kickoff", game.KickoffTime.Format(time.RFC3339),
-						"odds_kickoff", spread.CommenceTime.Format(time.RFC3339),
-					)
-					break
-				} else {
-					logger.Warn(
-						"teams match but kickoff times too different",
-						"game_id", game.ID,
-						"matchup", fmt.Sprintf("%s @ %s", game.AwayTeamAbbr, game.HomeTeamAbbr),
-						"db_kickoff", game.KickoffTime.Format(time.RFC3339),
-						"odds_kickoff", spread.CommenceTime.Format(time.RFC3339),
-						"time_diff_hours", math.Abs(float64(spread.CommenceTime.Sub(game.KickoffTime).Hours())),
-					)
-				}
-			}
+			matchedSpread := service.MatchGameSpread(game, spreads)
 
 			if matchedSpread != nil {
 				updates = append(updates, GameUpdate{
 					GameID:     game.ID,
 					HomeSpread: matchedSpread.HomeSpread,
+					Total:      matchedSpread.Total,
+					Lines:      matchedSpread.Lines,
 					Matched:    true,
 				})
+				sources = append(sources, GameSpreadSource{
+					GameID:     game.ID,
+					Matchup:    fmt.Sprintf("%s @ %s", game.AwayTeamAbbr, game.HomeTeamAbbr),
+					HomeSpread: matchedSpread.HomeSpread,
+					Total:      matchedSpread.Total,
+					Bookmaker:  matchedSpread.Bookmaker,
+					BookCount:  matchedSpread.BookCount,
+					SpreadMin:  matchedSpread.MinSpread,
+					SpreadMax:  matchedSpread.MaxSpread,
+				})
 				matchedCount++
 			} else {
 				unmatchedGames = append(unmatchedGames, fmt.Sprintf("%s @ %s (kickoff: %s)",
@@ -222,17 +233,19 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 		defer tx.Rollback()
 
 		// Update each matched game
+		historyRecorded := 0
 		for _, update := range updates {
 			if !update.Matched {
 				continue
 			}
 
+			// only overwrite the total when one came back, so a manually set total isn't wiped
 			query := `
 				UPDATE games
-				SET home_spread = $1, updated_at = NOW()
+				SET home_spread = $1, total = COALESCE($4, total), updated_at = NOW()
 				WHERE id = $2 AND week_id = $3
 			`
-			result, err := tx.Exec(query, update.HomeSpread, update.GameID, weekID)
+			result, err := tx.Exec(query, update.HomeSpread, update.GameID, weekID, update.Total)
 			if err != nil {
 				logger.Error(
 					"failed to update game spread",
@@ -251,6 +264,20 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 					"game_id", update.GameID,
 				)
 			}
+
+			// keep every bookmaker's line for line movement
+			recorded, err := service.RecordSpreadHistory(tx, update.GameID, update.Lines)
+			if err != nil {
+				logger.Error(
+					"failed to record spread history",
+					"game_id", update.GameID,
+					"error", err,
+				)
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record spread history"})
+				return
+			}
+			historyRecorded += recorded
 		}
 
 		// Update week status to spreads_set
@@ -282,14 +309,24 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 			"week_id", weekID,
 			"games_updated", matchedCount,
 			"games_total", len(games),
+			"method", method,
 		)
 
+		// consensus lines don't come from one bookmaker
+		if method != external.SpreadMethodBookmaker {
+			bookmaker = "consensus"
+		}
+
 		// set up the response here
 		response := gin.H{
-			"games_updated": matchedCount,
-			"games_total":   len(games),
-			"bookmaker":     bookmaker,
-			"week_status":   "spreads_set",
+			"games_updated":  matchedCount,
+			"games_total":    len(games),
+			"lines_recorded": historyRecorded,
+			"bookmaker":      bookmaker,
+			"method":         method,
+			"week_status":    "spreads_set",
+			"spreads":        sources,
+			"odds_quota":     external.CurrentOddsQuota(),
 		}
 
 		if len(unmatchedGames) > 0 {
@@ -300,3 +337,106 @@ func AutoImportSpreads(db *sqlx.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// RecordSpreadHistory fetches every bookmaker's current line for a week's games and only adds them to the
+// spread history.  Spreads aren't changed, so this works after the week is active to track line movement
+func RecordSpreadHistory(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		res, err := service.RecordWeekSpreadHistory(c.Request.Context(), db, weekID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found"})
+			case errors.Is(err, service.ErrWeekLinesNotRecordable):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":          "Can only record lines when week is in games_imported, spreads_set or active status",
+					"current_status": res.Status,
+				})
+			case errors.Is(err, external.ErrOddsQuotaLow):
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":      "Odds API quota is too low to fetch spreads.  Set spreads manually or wait for the quota to reset",
+					"odds_quota": external.CurrentOddsQuota(),
+				})
+			default:
+				c.Error(err)
+				c.JSON(providerErrorStatus(err), gin.H{
+					"error":   "Failed to record spread history",
+					"details": err.Error(),
+				})
+			}
+			return
+		}
+
+		logger.Info(
+			"recorded spread history",
+			"week_id", weekID,
+			"games_matched", res.GamesMatched,
+			"lines_recorded", res.LinesRecorded,
+		)
+
+		c.JSON(http.StatusOK, gin.H{
+			"week_id":        weekID,
+			"games_matched":  res.GamesMatched,
+			"games_total":    res.GamesTotal,
+			"lines_recorded": res.LinesRecorded,
+			"odds_quota":     external.CurrentOddsQuota(),
+		})
+	}
+}
+
+// GetGameSpreadHistory returns every recorded bookmaker line for a game so the line movement can be charted
+func GetGameSpreadHistory(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gameID := c.Param("game_id")
+
+		history, err := service.GetGameSpreadHistory(db, gameID)
+		if err != nil {
+			if errors.Is(err, service.ErrGameNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Game not found", "game_id": gameID})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch spread history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+// GetWeekLineMovement shows how far each game's line has moved since the week was activated.
+// ?threshold= sets how many points count as a big move
+func GetWeekLineMovement(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		weekID := c.Param("week_id")
+
+		threshold := service.DefaultLineMovementThreshold
+		if value := c.Query("threshold"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold", "threshold": value})
+				return
+			}
+			threshold = parsed
+		}
+
+		movement, err := service.GetWeekLineMovement(db, weekID, threshold)
+		if err != nil {
+			if errors.Is(err, service.ErrWeekNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found"})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch line movement"})
+			return
+		}
+
+		c.JSON(http.StatusOK, movement)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+// GetSurvivorStandings returns who is still alive in a survivor season, when everyone else was
+// eliminated, and which teams each participant has already used
+func GetSurvivorStandings(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seasonID := c.Param("season_id")
+		if seasonID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing season ID"})
+			return
+		}
+
+		var seasonType string
+		err := db.Get(&seasonType, `SELECT season_type FROM public.seasons WHERE id = $1`, seasonID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Season not found"})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		if seasonType != service.SeasonTypeSurvivor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Season is not a survivor season"})
+			return
+		}
+
+		standings, err := service.GetSurvivorStandings(db, seasonID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load survivor standings"})
+			return
+		}
+
+		alive := 0
+		for _, s := range standings {
+			if s.IsAlive {
+				alive++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"season_id":   seasonID,
+			"alive_count": alive,
+			"standings":   standings,
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/service"
 )
 
 // GetAllTeams returns all active teams in the database
@@ -29,3 +31,103 @@ func GetAllTeams(db *sqlx.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"teams": teams})
 	}
 }
+
+// teamAliasRequest is the body for creating or updating a team alias
+type teamAliasRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Alias    string `json:"alias" binding:"required"`
+	TeamID   string `json:"team_id" binding:"required"`
+}
+
+// GetTeamAliases lists provider team aliases.  ?provider= filters to one provider
+func GetTeamAliases(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider := c.Query("provider")
+		if provider != "" && !service.IsValidAliasProvider(provider) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown provider", "provider": provider})
+			return
+		}
+
+		aliases, err := service.GetTeamAliases(db, provider)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"aliases": aliases})
+	}
+}
+
+// CreateTeamAlias maps a provider's name for a team to one of our teams
+func CreateTeamAlias(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req teamAliasRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		alias, err := service.CreateTeamAlias(db, req.Provider, req.Alias, req.TeamID)
+		if err != nil {
+			respondTeamAliasError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"alias": alias})
+	}
+}
+
+// UpdateTeamAlias changes an existing alias
+func UpdateTeamAlias(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		aliasID := c.Param("alias_id")
+
+		var req teamAliasRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		alias, err := service.UpdateTeamAlias(db, aliasID, req.Provider, req.Alias, req.TeamID)
+		if err != nil {
+			respondTeamAliasError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"alias": alias})
+	}
+}
+
+// DeleteTeamAlias removes an alias
+func DeleteTeamAlias(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		aliasID := c.Param("alias_id")
+
+		if err := service.DeleteTeamAlias(db, aliasID); err != nil {
+			respondTeamAliasError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deleted": aliasID})
+	}
+}
+
+// respondTeamAliasError maps team alias service errors to responses
+func respondTeamAliasError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTeamAliasNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team alias not found"})
+	case errors.Is(err, service.ErrTeamNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team not found"})
+	case errors.Is(err, service.ErrInvalidAliasProvider):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown provider.  Must be any, odds, balldontlie or espn"})
+	case errors.Is(err, service.ErrTeamAliasRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alias is required"})
+	case errors.Is(err, service.ErrTeamAliasExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "That provider already has this alias"})
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/api/middleware"
 	"pawked.com/sendyourpicks/internal/logger"
 	"pawked.com/sendyourpicks/internal/models"
 	"pawked.com/sendyourpicks/internal/service"
+	"pawked.com/sendyourpicks/internal/settings"
 )
 
 // GetWeeks should be viewable by anyone, and will just return info about the weeks, including which is active
@@ -70,6 +74,7 @@ func GetWeek(db *sqlx.DB) gin.HandlerFunc {
 				w.updated_at,
 				w.created_by,
 				w.status,
+				w.totals_enabled,
 				s.year,
 				s.is_postseason
 			FROM public.weeks w
@@ -101,12 +106,17 @@ func GetWeek(db *sqlx.DB) gin.HandlerFunc {
 				g.home_score,
 				g.away_score,
 				g.home_spread,
+				g.total,
 				g.kickoff_time,
 				g.neutral_site,
 				g.status,
 				g.created_at,
 				g.updated_at,
 				g.created_by,
+				g.voided_at,
+				g.void_reason,
+				g.period,
+				g.clock,
 
 				ht.abbreviation AS home_team_abbr,
 				at.abbreviation AS away_team_abbr,
@@ -122,7 +132,7 @@ func GetWeek(db *sqlx.DB) gin.HandlerFunc {
 			INNER JOIN public.teams ht ON g.home_team_id = ht.id
 			INNER JOIN public.teams at ON g.away_team_id = at.id
 			WHERE g.week_id = $1
-			ORDER BY g.kickoff_time
+			ORDER BY g.kickoff_time, g.id
 		`
 
 		err = db.Select(&games, gamesQuery, weekID)
@@ -139,7 +149,33 @@ func GetWeek(db *sqlx.DB) gin.HandlerFunc {
 
 		week.Games = games
 
-		c.JSON(http.StatusOK, gin.H{"week": week})
+		// the tiebreaker game is the last one to kick off (same ordering as service.GetTiebreakerGame)
+		var tiebreakerGameID *string
+		if len(games) > 0 {
+			tiebreakerGameID = &games[len(games)-1].ID
+		}
+
+		// slates and pick deadlines are worked out in the competition timezone
+		s, err := settings.GetForWeek(db, weekID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load global settings"})
+			return
+		}
+
+		kickoffs := make(map[string]time.Time, len(games))
+		for _, game := range games {
+			kickoffs[game.ID] = game.KickoffTime
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"week":               week,
+			"tiebreaker_game_id": tiebreakerGameID,
+			"timezone":           s.CompetitionTimezone,
+			"pick_lock_rule":     s.PickLockRule,
+			"slates":             service.WeekSlates(games, s),
+			"pick_deadlines":     service.PickDeadlines(kickoffs, s),
+		})
 	}
 }
 
@@ -196,6 +232,8 @@ func UpdateSpreads(db *sqlx.DB) gin.HandlerFunc {
 		type GameSpreadUpdate struct {
 			GameID     string   `json:"game_id" binding:"required"`
 			HomeSpread *float64 `json:"home_spread"`
+			Total      *float64 `json:"total"`       // only used when the week has totals enabled.  Left alone when not sent
+			ClearTotal bool     `json:"clear_total"` // remove the game's total
 		}
 
 		type UpdateWeekRequest struct {
@@ -253,6 +291,20 @@ func UpdateSpreads(db *sqlx.DB) gin.HandlerFunc {
 					return
 				}
 			}
+
+			// totals go in half points too, and can't be negative
+			if gameUpdate.Total != nil {
+				total := *gameUpdate.Total
+				remainder := total - (float64(int(total/0.5)) * 0.5)
+				if total < 0 || remainder < -0.001 || remainder > 0.001 {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":   "Invalid total value. Totals must be positive multiples of 0.5 (e.g., 44.5, 51.0)",
+						"game_id": gameUpdate.GameID,
+						"total":   total,
+					})
+					return
+				}
+			}
 		}
 
 		// Start transaction
@@ -268,10 +320,12 @@ func UpdateSpreads(db *sqlx.DB) gin.HandlerFunc {
 		for _, gameUpdate := range req.Games {
 			query := `
                 UPDATE games
-                SET home_spread = $1, updated_at = NOW()
+                SET home_spread = $1,
+                    total = CASE WHEN $5 THEN NULL ELSE COALESCE($4, total) END,
+                    updated_at = NOW()
                 WHERE id = $2 AND week_id = $3
             `
-			result, err := tx.Exec(query, gameUpdate.HomeSpread, gameUpdate.GameID, weekID)
+			result, err := tx.Exec(query, gameUpdate.HomeSpread, gameUpdate.GameID, weekID, gameUpdate.Total, gameUpdate.ClearTotal)
 			if err != nil {
 				c.Error(err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update game"})
@@ -344,6 +398,65 @@ func UpdateSpreads(db *sqlx.DB) gin.HandlerFunc {
 	}
 }
 
+// SetWeekTotals turns the over/under market on or off for a week.  Can only be changed before the week is activated
+func SetWeekTotals(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		var req struct {
+			Enabled *bool `json:"enabled" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		week, err := service.GetWeekWithYear(db, weekID)
+		if err != nil {
+			if errors.Is(err, service.ErrWeekNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+				return
+			}
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
+		// survivor picks are a single side, there's nowhere to put a total
+		if week.SeasonType == service.SeasonTypeSurvivor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Survivor seasons don't support totals"})
+			return
+		}
+
+		if week.Status != service.StatusDraft && week.Status != service.StatusGamesImported && week.Status != service.StatusSpreadsSet {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":          "Totals can only be changed before the week is activated",
+				"current_status": week.Status,
+			})
+			return
+		}
+
+		_, err = db.Exec(`UPDATE weeks SET totals_enabled = $1, updated_at = NOW() WHERE id = $2`, *req.Enabled, weekID)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update week"})
+			return
+		}
+
+		logger.Info("week totals updated", "week_id", weekID, "totals_enabled", *req.Enabled)
+
+		c.JSON(http.StatusOK, gin.H{
+			"week_id":        weekID,
+			"totals_enabled": *req.Enabled,
+		})
+	}
+}
+
 // Just sets a week's status to active.  Currently used by the commissioner to release it to users
 func ActivateWeek(db *sqlx.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -404,13 +517,29 @@ func ActivateWeek(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Straight-up survivor seasons don't use spreads so they can't be missing.
+		// The share lock waits out UpdateSeasonSettings so the week activates under one set of rules
+		var straightUpSurvivor bool
+		err = tx.Get(&straightUpSurvivor, `
+            SELECT season_type = $2 AND NOT survivor_use_spread
+            FROM seasons
+            WHERE id = $1
+            FOR SHARE
+        `, week.SeasonID, service.SeasonTypeSurvivor)
+		if err != nil {
+			logger.Error("failed to fetch season for activation", "week_id", weekID, "error", err)
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+
 		// Make sure all games have a spread
 		var gamesWithoutSpreads int
 		err = tx.Get(&gamesWithoutSpreads, `
             SELECT COUNT(*)
             FROM games
-            WHERE week_id = $1 AND home_spread IS NULL
-        `, weekID)
+            WHERE week_id = $1 AND home_spread IS NULL AND NOT $2
+        `, weekID, straightUpSurvivor)
 		if err != nil {
 			logger.Error(
 				"failed to validate game spreads before activating week",
@@ -435,6 +564,39 @@ func ActivateWeek(db *sqlx.DB) gin.HandlerFunc {
 			return
 		}
 
+		// Weeks with totals turned on need a total on every game too
+		if week.TotalsEnabled {
+			var gamesWithoutTotals int
+			err = tx.Get(&gamesWithoutTotals, `
+                SELECT COUNT(*)
+                FROM games
+                WHERE week_id = $1 AND total IS NULL
+            `, weekID)
+			if err != nil {
+				logger.Error(
+					"failed to validate game totals before activating week",
+					"week_id", weekID,
+					"error", err,
+				)
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+
+			if gamesWithoutTotals > 0 {
+				logger.Warn(
+					"activate week blocked due to missing totals",
+					"week_id", weekID,
+					"games_missing_totals", gamesWithoutTotals,
+				)
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":                "Cannot activate week: some games are missing totals",
+					"games_missing_totals": gamesWithoutTotals,
+				})
+				return
+			}
+		}
+
 		// Update week status to active
 		_, err = tx.Exec(`
             UPDATE weeks
@@ -515,3 +677,97 @@ func GetActiveWeek(db *sqlx.DB) gin.HandlerFunc {
 		})
 	}
 }
+
+// RevertWeekState moves a week back to an earlier status, undoing whatever the later statuses calculated.
+// Needs a reason, which gets stored in week_reverts
+func RevertWeekState(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		var req struct {
+			Status string `json:"status" binding:"required"`
+			Reason string `json:"reason" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body.  status and reason are required"})
+			return
+		}
+
+		res, err := service.RevertWeekState(c.Request.Context(), db, weekID, req.Status, req.Reason, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+			case errors.Is(err, service.ErrRevertReasonRequired):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+			case errors.Is(err, service.ErrInvalidRevertTarget):
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Week can only be reverted to an earlier status (not draft)", "status": req.Status})
+			case errors.Is(err, service.ErrWeekNotLatest):
+				c.JSON(http.StatusConflict, gin.H{"error": "Only the latest week in a season can be reverted"})
+			default:
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revert week"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"week_id":            res.WeekID,
+			"from_status":        res.FromStatus,
+			"status":             res.ToStatus,
+			"standings_deleted":  res.StandingsDeleted,
+			"results_deleted":    res.ResultsDeleted,
+			"users_uneliminated": res.UsersUneliminated,
+			"picks_ungraded":     res.PicksUngraded,
+			"activation_cleared": res.ActivationCleared,
+		})
+	}
+}
+
+// RefreshWeekSchedule re-reads a week's games from the games provider and reports what was added, changed and
+// removed.  New games and kickoff changes are applied; new teams for a game and removed games (which clear
+// spreads and picks) are only applied with ?confirm=true
+func RefreshWeekSchedule(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := middleware.GetUserID(c)
+
+		weekID := c.Param("week_id")
+		if weekID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing week ID"})
+			return
+		}
+
+		confirm := c.Query("confirm") == "true"
+
+		res, err := service.RefreshWeekSchedule(c.Request.Context(), db, weekID, confirm, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrWeekNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Week not found", "week_id": weekID})
+			case errors.Is(err, service.ErrScheduleNotRefreshable):
+				c.JSON(http.StatusConflict, gin.H{"error": "Schedules can only be refreshed until the week has been played"})
+			case errors.Is(err, service.ErrScheduleChangeLocked):
+				c.JSON(http.StatusConflict, gin.H{
+					"error":  "The provider has new games or new teams for this week, which can't be added once the week is active.  Nothing was changed",
+					"locked": res.Locked,
+				})
+			case errors.Is(err, service.ErrTeamMappingFailed):
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Provider returned a team we don't know.  Add a team alias", "details": err.Error()})
+			case errors.Is(err, service.ErrExternalGameIDTaken):
+				c.JSON(http.StatusConflict, gin.H{"error": "A provider game is already linked to another game in this season"})
+			default:
+				c.Error(err)
+				c.JSON(providerErrorStatus(err), gin.H{"error": "Failed to refresh schedule", "details": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+// route params RequireLeagueRole checks belong to the league
+var leagueResourceParams = []string{"season_id", "week_id", "game_id"}
+
+// RequireLeagueRole returns middleware that restricts a /leagues/:league_id route to members of the league with
+// one of the specified league roles.  Admins can get into every league.  Any season, week or game in the route
+// has to belong to the league so a league's commissioners can't reach another league's seasons
+func RequireLeagueRole(db *sqlx.DB, allowedRoles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawClaims, ok := c.Get("claims")
+		claims, _ := rawClaims.(*SupabaseClaims)
+		if !ok || claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   AuthErrorUnauthorized,
+				"message": "authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		leagueID := c.Param("league_id")
+
+		if _, err := service.GetLeague(db, leagueID); err != nil {
+			if errors.Is(err, service.ErrLeagueNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "League not found"})
+			} else {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			c.Abort()
+			return
+		}
+
+		role, err := service.GetLeagueRole(db, leagueID, claims.Sub)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			c.Abort()
+			return
+		}
+
+		allowed := claims.UserRole == "admin"
+		for _, allowedRole := range allowedRoles {
+			if role == allowedRole {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   AuthErrorForbidden,
+				"message": "insufficient permissions for this league",
+			})
+			c.Abort()
+			return
+		}
+
+		for _, param := range leagueResourceParams {
+			resourceID := c.Param(param)
+			if resourceID == "" {
+				continue
+			}
+			if err := service.CheckLeagueResource(db, leagueID, param, resourceID); err != nil {
+				if errors.Is(err, service.ErrNotInLeague) {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Not found in this league"})
+				} else {
+					c.Error(err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				}
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("league_role", role)
+		c.Next()
+	}
+}
+
+// GetLeagueRole returns the user's role in the route's league ("" for admins who aren't members)
+func GetLeagueRole(c *gin.Context) string {
+	return c.GetString("league_role")
+}
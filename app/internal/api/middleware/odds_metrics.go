@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"pawked.com/sendyourpicks/internal/external"
+)
+
+// oddsQuotaCollector reports the Odds API quota from the most recent response.
+// Nothing is reported until the first Odds API call after startup
+type oddsQuotaCollector struct {
+	remaining *prometheus.Desc
+	used      *prometheus.Desc
+	lastCost  *prometheus.Desc
+	updatedAt *prometheus.Desc
+}
+
+// RegisterOddsMetrics creates and registers a collector for the Odds API quota.
+func RegisterOddsMetrics() {
+	collector := &oddsQuotaCollector{
+		remaining: prometheus.NewDesc(
+			"syp_odds_api_requests_remaining",
+			"Odds API requests remaining this month, from the last response.",
+			nil, nil,
+		),
+		used: prometheus.NewDesc(
+			"syp_odds_api_requests_used",
+			"Odds API requests used this month, from the last response.",
+			nil, nil,
+		),
+		lastCost: prometheus.NewDesc(
+			"syp_odds_api_last_request_cost",
+			"Quota used by the last Odds API request.",
+			nil, nil,
+		),
+		updatedAt: prometheus.NewDesc(
+			"syp_odds_api_quota_updated_timestamp_seconds",
+			"When the Odds API quota was last read, as a unix timestamp.",
+			nil, nil,
+		),
+	}
+	prometheus.MustRegister(collector)
+}
+
+func (c *oddsQuotaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.remaining
+	ch <- c.used
+	ch <- c.lastCost
+	ch <- c.updatedAt
+}
+
+func (c *oddsQuotaCollector) Collect(ch chan<- prometheus.Metric) {
+	quota := external.CurrentOddsQuota()
+	if quota == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.remaining, prometheus.GaugeValue, float64(quota.Remaining))
+	ch <- prometheus.MustNewConstMetric(c.used, prometheus.GaugeValue, float64(quota.Used))
+	ch <- prometheus.MustNewConstMetric(c.lastCost, prometheus.GaugeValue, float64(quota.LastCost))
+	ch <- prometheus.MustNewConstMetric(c.updatedAt, prometheus.GaugeValue, float64(quota.UpdatedAt.Unix()))
+}
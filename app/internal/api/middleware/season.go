@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/service"
+)
+
+// route params RequireSeasonRole looks up the season from, in order
+var seasonRouteParams = []string{"season_id", "week_id", "game_id"}
+
+// RequireSeasonRole returns middleware that restricts a route to users with one of the specified roles in the
+// season the route is about.  The season comes from :season_id, or the season of :week_id or :game_id.
+// Admins can get into every season
+func RequireSeasonRole(db *sqlx.DB, allowedRoles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawClaims, ok := c.Get("claims")
+		claims, _ := rawClaims.(*SupabaseClaims)
+		if !ok || claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   AuthErrorUnauthorized,
+				"message": "authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		seasonID, err := routeSeasonID(c, db)
+		if err != nil {
+			if errors.Is(err, service.ErrSeasonNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			} else {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			c.Abort()
+			return
+		}
+
+		role, err := service.GetSeasonRole(db, seasonID, claims.Sub)
+		if err != nil {
+			c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			c.Abort()
+			return
+		}
+
+		allowed := claims.UserRole == "admin"
+		for _, allowedRole := range allowedRoles {
+			if role == allowedRole {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   AuthErrorForbidden,
+				"message": "insufficient permissions for this season",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("season_role", role)
+		c.Next()
+	}
+}
+
+// GetSeasonRole returns the user's role in the route's season ("" for admins without one)
+func GetSeasonRole(c *gin.Context) string {
+	return c.GetString("season_role")
+}
+
+// RequireSeasonViewer returns middleware that restricts a route to users who can see the season the route is
+// about (the season of :season_id, :week_id or :game_id): members of its league and anyone with a role in the
+// season.  Admins can see every season
+func RequireSeasonViewer(db *sqlx.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawClaims, ok := c.Get("claims")
+		claims, _ := rawClaims.(*SupabaseClaims)
+		if !ok || claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   AuthErrorUnauthorized,
+				"message": "authentication required",
+			})
+			c.Abort()
+			return
+		}
+
+		seasonID, err := routeSeasonID(c, db)
+		if err != nil {
+			if errors.Is(err, service.ErrSeasonNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			} else {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			c.Abort()
+			return
+		}
+
+		if claims.UserRole != "admin" {
+			canView, err := service.CanViewSeason(db, seasonID, claims.Sub)
+			if err != nil {
+				c.Error(err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				c.Abort()
+				return
+			}
+			if !canView {
+				// same as a missing season so other leagues' ids don't leak
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// routeSeasonID finds the season from the first of :season_id, :week_id or :game_id in the route
+func routeSeasonID(c *gin.Context, db *sqlx.DB) (string, error) {
+	for _, param := range seasonRouteParams {
+		if resourceID := c.Param(param); resourceID != "" {
+			return service.SeasonIDForRoute(db, param, resourceID)
+		}
+	}
+	return "", service.ErrUnknownSeasonRoute
+}
@@ -35,56 +35,78 @@ func RegisterRoutes(r *gin.Engine, db *sqlx.DB, authConfig *middleware.AuthConfi
 	api.GET("/teams", handlers.GetAllTeams(db)) // lists all active teams
 
 	// Get info about seasons and weeks
-	api.GET("/seasons", handlers.GetAllSeasons(db))                                 // lists metadata for all seasons
-	api.GET("/seasons/active", handlers.GetActiveSeason(db))                        // see active season
-	api.GET("/seasons/:season_id", handlers.GetSeason(db))                          // returns metadata and games for a single season
-	api.GET("/seasons/:season_id/weeks/active", handlers.GetActiveWeek(db))         // get an active week if it exists
-	api.GET("/seasons/:season_id/participants", handlers.GetSeasonParticipants(db)) // list users participating in this season
-	api.GET("/weeks/:week_id", handlers.GetWeek(db))                                // get all games for a week
+	api.GET("/seasons", handlers.GetAllSeasons(db))          // lists metadata for the seasons I can see
+	api.GET("/seasons/active", handlers.GetActiveSeason(db)) // see active season (league members only)
+
+	// routes about one season, week or game: members of the season's league only
+	season := api.Group("")
+	season.Use(middleware.RequireSeasonViewer(db))
+	season.GET("/seasons/:season_id", handlers.GetSeason(db))                          // returns metadata and games for a single season
+	season.GET("/seasons/:season_id/settings", handlers.GetSeasonSettings(db))         // scoring and pick rules for a season
+	season.GET("/seasons/:season_id/role", handlers.GetMySeasonRole(db))               // my role in a season
+	season.GET("/seasons/:season_id/weeks/active", handlers.GetActiveWeek(db))         // get an active week if it exists
+	season.GET("/seasons/:season_id/participants", handlers.GetSeasonParticipants(db)) // list users participating in this season
+	season.GET("/weeks/:week_id", handlers.GetWeek(db))                                // get all games for a week
+	season.GET("/games/:game_id/spreads/history", handlers.GetGameSpreadHistory(db))   // every recorded bookmaker line for a game
 
 	// Picks related
-	api.PUT("/weeks/:week_id/picks", handlers.SubmitPicks(db))                  // make and edit picks for logged in user
-	api.GET("/weeks/:week_id/picks", handlers.GetMyPicks(db))                   // get my picks for the week
-	api.GET("/weeks/:week_id/picks/summary", handlers.GetMyWeekPickSummary(db)) // returns a summary of my picks for a week
-	api.POST("/weeks/:week_id/picks/lock", handlers.LockWeekPicks(db))          // locks all a user's picks for the week
-	api.GET("/weeks/:week_id/picks/locked", handlers.GetWeekLockedPicks(db))    // returns all locked picks for all users for the week
+	season.PUT("/weeks/:week_id/picks", handlers.SubmitPicks(db))                  // make and edit picks for logged in user
+	season.GET("/weeks/:week_id/picks", handlers.GetMyPicks(db))                   // get my picks for the week
+	season.GET("/weeks/:week_id/picks/summary", handlers.GetMyWeekPickSummary(db)) // returns a summary of my picks for a week
+	season.POST("/weeks/:week_id/picks/lock", handlers.LockWeekPicks(db))          // locks all a user's picks for the week
+	season.GET("/weeks/:week_id/picks/locked", handlers.GetWeekLockedPicks(db))    // returns all locked picks for all users for the week
 
 	// Points and standings related
-	api.GET("/weeks/:week_id/results", handlers.GetWeekResults(db))                       // get the results for a week for all users
-	api.GET("/weeks/:week_id/standings", handlers.GetSeasonStandings(db))                 // gets the season standings after a given week
-	api.GET("/seasons/:season_id/points", handlers.GetMySeasonPoints(db))                 // returns my per week points and standings
-	api.GET("/seasons/:season_id/standings", handlers.GetCurrentSeasonStandings(db))      // Current: latest standings for the season
-	api.GET("/seasons/:season_id/standings/me", handlers.GetMyCurrentSeasonStandings(db)) // gets logged in users current standings for the season
-	api.GET("/seasons/:season_id/week-winners", handlers.GetWeekWinners(db))              // who won each week (with ties)
-	api.GET("/seasons/:season_id/win-counts", handlers.GetUserWinCounts(db))              // user win/tie counts
+	season.GET("/weeks/:week_id/results", handlers.GetWeekResults(db))                       // get the results for a week for all users
+	season.GET("/weeks/:week_id/results/provisional", handlers.GetProvisionalResults(db))    // "if the games ended now" results from live scores (never saved)
+	season.GET("/weeks/:week_id/standings", handlers.GetSeasonStandings(db))                 // gets the season standings after a given week
+	season.GET("/seasons/:season_id/points", handlers.GetMySeasonPoints(db))                 // returns my per week points and standings
+	season.GET("/seasons/:season_id/standings", handlers.GetCurrentSeasonStandings(db))      // Current: latest standings for the season
+	season.GET("/seasons/:season_id/standings/me", handlers.GetMyCurrentSeasonStandings(db)) // gets logged in users current standings for the season
+	season.GET("/seasons/:season_id/week-winners", handlers.GetWeekWinners(db))              // who won each week (with ties)
+	season.GET("/seasons/:season_id/win-counts", handlers.GetUserWinCounts(db))              // user win/tie counts
+	season.GET("/seasons/:season_id/survivor", handlers.GetSurvivorStandings(db))            // survivor seasons: who is alive and which teams they've used
 
 	// Chart related
-	api.GET("/seasons/:season_id/standings/history", handlers.GetSeasonHistory(db)) // returns the point and ranking history of the season for graphing
+	season.GET("/seasons/:season_id/standings/history", handlers.GetSeasonHistory(db)) // returns the point and ranking history of the season for graphing
 
-	// Commissioner routes (commissioner or admin)
+	// Commissioner routes.  Commissioners create seasons, then manage the seasons they own or co-commission (admins manage every season)
+	api.POST("/commissioner/seasons", middleware.RequireRole("commissioner", "admin"), handlers.NewSeason(db)) // create a new season (I become its owner)
 	commissioner := api.Group("/commissioner")
-	commissioner.Use(middleware.RequireRole("commissioner", "admin"))
+	commissioner.Use(middleware.RequireSeasonRole(db, "owner", "co_commissioner"))
+	registerCommissionerRoutes(commissioner, db)
+
+	// Leagues
+	api.GET("/leagues", handlers.GetMyLeagues(db))  // leagues I'm in, with my role in each
+	api.POST("/leagues", handlers.CreateLeague(db)) // create a league (I become its owner)
+
+	// League member routes (any member of the league, or admin)
+	league := api.Group("/leagues/:league_id")
+	league.Use(middleware.RequireLeagueRole(db, "owner", "commissioner", "member"))
+	{
+		league.GET("", handlers.GetLeague(db))                      // league info and members
+		league.GET("/seasons", handlers.GetAllSeasons(db))          // seasons in the league
+		league.GET("/seasons/active", handlers.GetActiveSeason(db)) // the league's active season (?type=survivor)
+		league.GET("/badges", handlers.GetBadges(db))               // badges based on the league's active pick'em season
+		league.GET("/settings", handlers.GetLeagueSettings(db))     // the league's timezone and the rules its new seasons start with
+	}
+
+	// League owner routes
+	leagueOwner := api.Group("/leagues/:league_id/members")
+	leagueOwner.Use(middleware.RequireLeagueRole(db, "owner"))
 	{
-		// Season Management
-		commissioner.POST("/seasons", handlers.NewSeason(db))                                 // create a new season
-		commissioner.POST("/seasons/:season_id/advance", handlers.AdvanceSeason(db))          // advance the state of the season (state machine)
-		commissioner.PATCH("/seasons/:season_id/activate", handlers.ActivateSeason(db))       // sets the active season
-		commissioner.PATCH("/seasons/:season_id/deactivate", handlers.DeactivateSeason(db))   // deactivates the active season
-		commissioner.PATCH("/seasons/:season_id/weeks-count", handlers.UpdateSeasonWeeks(db)) // correct the number of weeks
-
-		// Participant Management
-		commissioner.POST("/seasons/:season_id/participants", handlers.AddSeasonParticipants(db))              // add user(s) to a season
-		commissioner.DELETE("/seasons/:season_id/participants/:user_id", handlers.RemoveSeasonParticipant(db)) // remove a user from a season
-
-		// Week Management (manual steps only)
-		commissioner.PUT("/weeks/:week_id/spreads", handlers.UpdateSpreads(db))                  // edit week spreads
-		commissioner.POST("/weeks/:week_id/spreads/auto-import", handlers.AutoImportSpreads(db)) // auto-import spreads from Odds API
-		commissioner.POST("/weeks/:week_id/activate", handlers.ActivateWeek(db))                 // activates a week
-
-		// Pick Management
-		commissioner.GET("/weeks/:week_id/picks", handlers.GetWeekPickSummary(db)) // returns user pick summaries for a week
+		leagueOwner.POST("", handlers.AddLeagueMember(db))                  // add a user to the league
+		leagueOwner.PATCH("/:user_id", handlers.UpdateLeagueMemberRole(db)) // change a member's role (owner, commissioner or member)
+		leagueOwner.DELETE("/:user_id", handlers.RemoveLeagueMember(db))    // remove a user from the league
 	}
 
+	// League commissioner routes: the commissioner routes limited to the league's own seasons, weeks and games
+	leagueCommissioner := api.Group("/leagues/:league_id/commissioner")
+	leagueCommissioner.Use(middleware.RequireLeagueRole(db, "owner", "commissioner"))
+	leagueCommissioner.POST("/seasons", handlers.NewSeason(db))            // create a new season in the league (I become its owner)
+	leagueCommissioner.PUT("/settings", handlers.UpdateLeagueSettings(db)) // set the league's timezone and new season rules (null uses the global setting)
+	registerCommissionerRoutes(leagueCommissioner, db)
+
 	// Admin-only routes
 	admin := api.Group("/admin")
 	admin.Use(middleware.RequireRole("admin"))
@@ -92,5 +114,55 @@ func RegisterRoutes(r *gin.Engine, db *sqlx.DB, authConfig *middleware.AuthConfi
 		// admin.DELETE("/users/:id", handlers.DeleteUser(db))
 		admin.GET("/users", handlers.GetAllAccounts(db))
 		admin.PUT("/settings", handlers.UpdateGlobalSettings(db))
+
+		// season roles
+		admin.GET("/seasons/:season_id/roles", handlers.GetSeasonRoles(db))               // everyone's role in a season
+		admin.PUT("/seasons/:season_id/roles/:user_id", handlers.SetSeasonRole(db))       // assign a user's season role
+		admin.DELETE("/seasons/:season_id/roles/:user_id", handlers.RemoveSeasonRole(db)) // take away a user's season role
+
+		// provider team name mapping
+		admin.GET("/team-aliases", handlers.GetTeamAliases(db))               // list team aliases (?provider=)
+		admin.POST("/team-aliases", handlers.CreateTeamAlias(db))             // map a provider's team name to a team
+		admin.PUT("/team-aliases/:alias_id", handlers.UpdateTeamAlias(db))    // change an alias
+		admin.DELETE("/team-aliases/:alias_id", handlers.DeleteTeamAlias(db)) // remove an alias
 	}
 }
+
+// registerCommissionerRoutes adds the management routes for existing seasons, weeks and games.  They're mounted at
+// /commissioner for a season's owner and co-commissioners and at /leagues/:league_id/commissioner for a league's commissioners
+func registerCommissionerRoutes(r *gin.RouterGroup, db *sqlx.DB) {
+	// Season Management
+	r.POST("/seasons/:season_id/advance", handlers.AdvanceSeason(db))               // advance the state of the season (state machine)
+	r.PATCH("/seasons/:season_id/activate", handlers.ActivateSeason(db))            // sets the active season
+	r.PATCH("/seasons/:season_id/deactivate", handlers.DeactivateSeason(db))        // deactivates the active season
+	r.PATCH("/seasons/:season_id/weeks-count", handlers.UpdateSeasonWeeks(db))      // correct the number of weeks
+	r.PATCH("/seasons/:season_id/push-policy", handlers.UpdateSeasonPushPolicy(db)) // change how pushes are scored (before any picks are graded)
+	r.PUT("/seasons/:season_id/settings", handlers.UpdateSeasonSettings(db))        // change a season's scoring and pick rules (before week 1 is activated)
+
+	// Participant Management
+	r.POST("/seasons/:season_id/participants", handlers.AddSeasonParticipants(db))              // add user(s) to a season
+	r.DELETE("/seasons/:season_id/participants/:user_id", handlers.RemoveSeasonParticipant(db)) // remove a user from a season
+
+	// Week Management (manual steps only)
+	r.PUT("/weeks/:week_id/spreads", handlers.UpdateSpreads(db))                  // edit week spreads
+	r.POST("/weeks/:week_id/spreads/auto-import", handlers.AutoImportSpreads(db)) // auto-import spreads from Odds API
+	r.POST("/weeks/:week_id/games/import", handlers.ImportManualGames(db))        // add games (and spreads) from a CSV or JSON upload
+	r.POST("/weeks/:week_id/games", handlers.CreateGame(db))                      // add a single game (before the week is activated)
+	r.GET("/weeks/:week_id/games/audit", handlers.GetGameAuditLog(db))            // every game create, edit and delete for a week
+	r.POST("/weeks/:week_id/games/refresh", handlers.RefreshWeekSchedule(db))     // update games from the provider without losing spreads or picks
+	r.POST("/weeks/:week_id/spreads/history", handlers.RecordSpreadHistory(db))   // record current bookmaker lines without changing spreads
+	r.GET("/weeks/:week_id/line-movement", handlers.GetWeekLineMovement(db))      // games whose line moved since activation
+	r.PATCH("/weeks/:week_id/totals", handlers.SetWeekTotals(db))                 // turn over/under picks on or off for a week
+	r.POST("/weeks/:week_id/activate", handlers.ActivateWeek(db))                 // activates a week
+	r.POST("/weeks/:week_id/revert", handlers.RevertWeekState(db))                // move a week back to an earlier status (needs a reason)
+
+	// Game Management
+	r.PATCH("/games/:game_id/result", handlers.CorrectGameResult(db)) // correct a played game's score and recompute results/standings
+	r.POST("/games/:game_id/void", handlers.VoidGame(db))             // void a game so it doesn't count for anyone
+	r.PATCH("/games/:game_id", handlers.UpdateGame(db))               // edit a game's teams, kickoff or external id (before the week is activated)
+	r.DELETE("/games/:game_id", handlers.DeleteGame(db))              // delete a game (before the week is activated)
+
+	// Pick Management
+	r.GET("/weeks/:week_id/picks", handlers.GetWeekPickSummary(db))    // returns user pick summaries for a week
+	r.PUT("/weeks/:week_id/picks/:user_id", handlers.OverridePick(db)) // set or clear a user's pick (when overrides are allowed)
+}
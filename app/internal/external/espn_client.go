@@ -0,0 +1,210 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultESPNBaseURL = "https://site.api.espn.com/apis/site/v2/sports/football/nfl"
+
+// ESPN scoreboard structures.  Only the fields i need
+type espnScoreboard struct {
+	Events []espnEvent `json:"events"`
+}
+
+type espnEvent struct {
+	ID           string            `json:"id"`
+	Date         string            `json:"date"` // "2024-09-06T00:20Z", no seconds so it isn't RFC3339
+	Competitions []espnCompetition `json:"competitions"`
+}
+
+type espnCompetition struct {
+	Competitors []espnCompetitor `json:"competitors"`
+	Status      espnStatus       `json:"status"`
+}
+
+type espnCompetitor struct {
+	HomeAway string `json:"homeAway"`
+	Score    string `json:"score"`
+	Team     struct {
+		Abbreviation string `json:"abbreviation"`
+	} `json:"team"`
+}
+
+type espnStatus struct {
+	DisplayClock string `json:"displayClock"`
+	Period       int    `json:"period"`
+	Type         struct {
+		Name      string `json:"name"`  // STATUS_FINAL, STATUS_IN_PROGRESS, ...
+		State     string `json:"state"` // pre, in, post
+		Completed bool   `json:"completed"`
+	} `json:"type"`
+}
+
+// ESPNClient reads the ESPN style scoreboard JSON feed
+type ESPNClient struct {
+	httpClient *http.Client
+	baseURL    string
+	retry      RetryPolicy
+}
+
+// NewESPNClient creates a new ESPN scoreboard client.  ESPN_API_BASE_URL is optional
+func NewESPNClient() (*ESPNClient, error) {
+	baseURL := os.Getenv("ESPN_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultESPNBaseURL
+	}
+
+	return &ESPNClient{
+		baseURL: baseURL,
+		retry:   DefaultRetryPolicy,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+func (c *ESPNClient) Name() string {
+	return ProviderESPN
+}
+
+// FetchWeekGames retrieves all games for a given season week from the ESPN scoreboard
+func (c *ESPNClient) FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error) {
+	u, err := url.Parse(c.baseURL + "/scoreboard")
+	if err != nil {
+		return nil, err
+	}
+
+	// ESPN season types: 2 is the regular season, 3 is the postseason.
+	// Postseason week 4 is the Pro Bowl here too, so weeks 4+ shift by 1
+	seasonType := 2
+	apiWeek := week
+	if postseason {
+		seasonType = 3
+		if week >= 4 {
+			apiWeek = week + 1
+		}
+	}
+
+	q := u.Query()
+	q.Add("dates", fmt.Sprint(season))
+	q.Add("seasontype", fmt.Sprint(seasonType))
+	q.Add("week", fmt.Sprint(apiWeek))
+	q.Add("limit", "100")
+	u.RawQuery = q.Encode()
+
+	resp, err := c.retry.do(ctx, c.httpClient, ProviderESPN, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed espnScoreboard
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	games := make([]ProviderGame, 0, len(parsed.Events))
+	for _, event := range parsed.Events {
+		game, err := event.toProviderGame()
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+// toProviderGame converts an ESPN event to the neutral ProviderGame
+func (e espnEvent) toProviderGame() (ProviderGame, error) {
+	if len(e.Competitions) == 0 {
+		return ProviderGame{}, fmt.Errorf("espn event %s has no competition", e.ID)
+	}
+	competition := e.Competitions[0]
+
+	externalID, err := strconv.ParseInt(e.ID, 10, 64)
+	if err != nil {
+		return ProviderGame{}, fmt.Errorf("espn event id %q: %w", e.ID, err)
+	}
+
+	kickoff, err := parseESPNTime(e.Date)
+	if err != nil {
+		return ProviderGame{}, fmt.Errorf("espn event %s date: %w", e.ID, err)
+	}
+
+	game := ProviderGame{
+		ExternalID:  externalID,
+		KickoffTime: kickoff,
+		Status:      espnGameStatus(competition.Status),
+	}
+
+	// ESPN sends "0" scores before kickoff, so only use them once the game has started
+	started := game.Status == GameStatusInProgress || game.Status == GameStatusFinal
+	for _, competitor := range competition.Competitors {
+		var score *int
+		if started {
+			if value, err := strconv.Atoi(competitor.Score); err == nil {
+				score = &value
+			}
+		}
+
+		switch competitor.HomeAway {
+		case "home":
+			game.HomeTeamAbbr = competitor.Team.Abbreviation
+			game.HomeScore = score
+		case "away":
+			game.AwayTeamAbbr = competitor.Team.Abbreviation
+			game.AwayScore = score
+		}
+	}
+
+	if game.Status == GameStatusInProgress {
+		period := competition.Status.Period
+		clock := competition.Status.DisplayClock
+		game.Period = &period
+		game.Clock = &clock
+	}
+
+	return game, nil
+}
+
+// espnGameStatus maps an ESPN status to a neutral game status
+func espnGameStatus(status espnStatus) string {
+	switch status.Type.Name {
+	case "STATUS_POSTPONED":
+		return GameStatusPostponed
+	case "STATUS_CANCELED", "STATUS_CANCELLED":
+		return GameStatusCancelled
+	}
+
+	switch status.Type.State {
+	case "in":
+		return GameStatusInProgress
+	case "post":
+		if status.Type.Completed {
+			return GameStatusFinal
+		}
+	}
+	return GameStatusScheduled
+}
+
+// parseESPNTime handles ESPN's minute precision dates as well as full RFC3339
+func parseESPNTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02T15:04Z07:00", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
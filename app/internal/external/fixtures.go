@@ -0,0 +1,175 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pawked.com/sendyourpicks/internal/logger"
+)
+
+// PROVIDER_FIXTURES modes.  Record saves every provider response to PROVIDER_FIXTURES_DIR, replay serves
+// them back without touching the network (or needing any API keys), so a season can be run offline
+const (
+	FixturesRecord = "record"
+	FixturesReplay = "replay"
+)
+
+const (
+	ProviderReplay      = "replay"
+	defaultFixturesDir  = "fixtures"
+	fixtureKindGames    = "games"
+	fixtureKindOdds     = "odds"
+	fixtureFileOddsLive = "latest.json" // odds fetched without a week, see OddsClient.ForWeek
+)
+
+// fixturesMode returns the PROVIDER_FIXTURES mode, or "" when fixtures are off
+func fixturesMode() (string, error) {
+	mode := strings.ToLower(os.Getenv("PROVIDER_FIXTURES"))
+	switch mode {
+	case "", FixturesRecord, FixturesReplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown PROVIDER_FIXTURES mode %q", mode)
+}
+
+func fixturesDir() string {
+	if dir := os.Getenv("PROVIDER_FIXTURES_DIR"); dir != "" {
+		return dir
+	}
+	return defaultFixturesDir
+}
+
+// fixtureWeek identifies the week a fixture belongs to
+type fixtureWeek struct {
+	Season     int
+	Week       int
+	Postseason bool
+}
+
+// fixturePath builds dir/kind/season/week-NN.json (postseason-week-NN.json for the postseason)
+func fixturePath(dir string, kind string, w fixtureWeek) string {
+	name := fmt.Sprintf("week-%02d.json", w.Week)
+	if w.Postseason {
+		name = "postseason-" + name
+	}
+	return filepath.Join(dir, kind, fmt.Sprint(w.Season), name)
+}
+
+// writeFixture saves v as indented JSON.  It writes to a temp file first so a crash never leaves half a fixture
+func writeFixture(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readFixture loads a fixture into v.  A missing fixture is an error that says which file to record
+func readFixture(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no fixture at %s, record one with PROVIDER_FIXTURES=record: %w", path, err)
+		}
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// gamesFixture is a recorded games response and the provider it came from, so a replay resolves team names with
+// the same provider's aliases the live import used
+type gamesFixture struct {
+	Provider string         `json:"provider"`
+	Games    []ProviderGame `json:"games"`
+}
+
+// readGamesFixture loads a games fixture.  Hand-written fixtures can be a bare array of games, which replay
+// under ProviderReplay
+func readGamesFixture(path string) (*gamesFixture, error) {
+	var raw json.RawMessage
+	if err := readFixture(path, &raw); err != nil {
+		return nil, err
+	}
+
+	fixture := &gamesFixture{}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(trimmed, &fixture.Games)
+		return fixture, err
+	}
+	err := json.Unmarshal(raw, fixture)
+	return fixture, err
+}
+
+// recordingProvider passes requests to the real provider and saves what comes back
+type recordingProvider struct {
+	provider GamesProvider
+	dir      string
+}
+
+func (p *recordingProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *recordingProvider) FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error) {
+	games, err := p.provider.FetchWeekGames(ctx, season, week, postseason)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed recording shouldn't fail the import
+	path := fixturePath(p.dir, fixtureKindGames, fixtureWeek{season, week, postseason})
+	if err := writeFixture(path, gamesFixture{Provider: p.provider.Name(), Games: games}); err != nil {
+		logger.Warn("failed to record games fixture", "path", path, "error", err)
+	} else {
+		logger.Debug("recorded games fixture", "path", path, "games", len(games))
+	}
+
+	return games, nil
+}
+
+// ReplayProvider serves games from recorded fixtures.  Each fetch re-reads the file, so editing a fixture
+// (say, to add final scores) moves the week along on the next score import
+type ReplayProvider struct {
+	dir string
+
+	// provider the last fixture was recorded from
+	provider string
+}
+
+// NewReplayProvider serves fixtures from dir
+func NewReplayProvider(dir string) *ReplayProvider {
+	return &ReplayProvider{dir: dir}
+}
+
+// Name is the provider the last fetched fixture was recorded from, so team aliases resolve the same way they did
+// live.  ProviderReplay before the first fetch and for fixtures that don't name one
+func (p *ReplayProvider) Name() string {
+	if p.provider != "" {
+		return p.provider
+	}
+	return ProviderReplay
+}
+
+func (p *ReplayProvider) FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error) {
+	path := fixturePath(p.dir, fixtureKindGames, fixtureWeek{season, week, postseason})
+	fixture, err := readGamesFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	p.provider = fixture.Provider
+	return fixture.Games, nil
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -28,25 +29,72 @@ type ExternalGame struct {
 	Date          time.Time    `json:"date"`
 	HomeTeam      ExternalTeam `json:"home_team"`
 	AwayTeam      ExternalTeam `json:"visitor_team"`
-	Status        string       `json:"status"` // i'm only going to care about "Final"
+	Status        string       `json:"status"` // mapped to a neutral status by ballDontLieStatus
 	HomeTeamScore *int         `json:"home_team_score"`
 	AwayTeamScore *int         `json:"visitor_team_score"`
+	Period        *int         `json:"quarter"` // only sent while the game is in progress
+	Clock         *string      `json:"time"`    // only sent while the game is in progress
 	IsPostseason  bool         `json:"postseason"`
 }
 
+// toProviderGame converts a BallDontLie game to the neutral ProviderGame
+func (g ExternalGame) toProviderGame() ProviderGame {
+	return ProviderGame{
+		ExternalID:   g.ID,
+		KickoffTime:  g.Date,
+		HomeTeamAbbr: g.HomeTeam.Abbreviation,
+		AwayTeamAbbr: g.AwayTeam.Abbreviation,
+		Status:       ballDontLieStatus(g.Status),
+		HomeScore:    g.HomeTeamScore,
+		AwayScore:    g.AwayTeamScore,
+		Period:       g.Period,
+		Clock:        g.Clock,
+	}
+}
+
+// ballDontLieStatus maps a BallDontLie status to a neutral game status.
+// Scheduled games come through as their kickoff time so anything unrecognised is scheduled
+func ballDontLieStatus(providerStatus string) string {
+	status := strings.ToLower(strings.TrimSpace(providerStatus))
+	switch status {
+	case "final", "final/ot":
+		return GameStatusFinal
+	case "postponed":
+		return GameStatusPostponed
+	case "canceled", "cancelled":
+		return GameStatusCancelled
+	}
+
+	// in progress games come through as things like "2nd Qtr", "Halftime" or "End of 3rd"
+	for _, live := range []string{"qtr", "quarter", "half", "overtime", "end of", "in progress"} {
+		if strings.Contains(status, live) {
+			return GameStatusInProgress
+		}
+	}
+	return GameStatusScheduled
+}
+
 // API wrapper response
 type gamesResponse struct {
 	Data []ExternalGame `json:"data"`
 	Meta struct {
-		NextCursor *string `json:"next_cursor"`
+		NextCursor *json.Number `json:"next_cursor"`
 	} `json:"meta"`
 }
 
+// BallDontLie pages are 25 games by default, 100 is the most it allows.
+// A week is never more than 16 games, so more than a handful of pages means something is wrong
+const (
+	ballDontLiePageSize = 100
+	ballDontLieMaxPages = 10
+)
+
 // BallDontLieClient is the HTTP client for the BallDontLie NFL API
 type BallDontLieClient struct {
 	httpClient *http.Client
 	apiKey     string
 	baseURL    string
+	retry      RetryPolicy
 }
 
 // NewBallDontLieClient creates a new BallDontLie API client from environment variables
@@ -64,12 +112,31 @@ func NewBallDontLieClient() (*BallDontLieClient, error) {
 	return &BallDontLieClient{
 		apiKey:  apiKey,
 		baseURL: baseURL,
+		retry:   DefaultRetryPolicy,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}, nil
 }
 
+func (c *BallDontLieClient) Name() string {
+	return ProviderBallDontLie
+}
+
+// FetchWeekGames retrieves all games for a given season week as neutral ProviderGames
+func (c *BallDontLieClient) FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error) {
+	externalGames, err := c.FetchGames(ctx, season, week, postseason)
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]ProviderGame, 0, len(externalGames))
+	for _, g := range externalGames {
+		games = append(games, g.toProviderGame())
+	}
+	return games, nil
+}
+
 // FetchGames retrieves all games for a given season week from the BallDontLie API
 func (c *BallDontLieClient) FetchGames(ctx context.Context, season int, week int, postseason bool) ([]ExternalGame, error) {
 
@@ -93,30 +160,48 @@ func (c *BallDontLieClient) FetchGames(ctx context.Context, season int, week int
 	} else {
 		q.Add("postseason", "false") // only request regular season games
 	}
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, err
+	q.Set("per_page", fmt.Sprint(ballDontLiePageSize))
+
+	// follow next_cursor until the last page so big weeks aren't cut off
+	games := make([]ExternalGame, 0)
+	for page := 1; ; page++ {
+		if page > ballDontLieMaxPages {
+			return nil, fmt.Errorf("external API (balldontlie) returned more than %d pages of games", ballDontLieMaxPages)
+		}
+
+		u.RawQuery = q.Encode()
+		parsed, err := c.fetchGamesPage(ctx, u.String())
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, parsed.Data...)
+
+		if parsed.Meta.NextCursor == nil || parsed.Meta.NextCursor.String() == "" {
+			return games, nil
+		}
+		q.Set("cursor", parsed.Meta.NextCursor.String())
 	}
+}
 
-	req.Header.Set("Authorization", c.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+// fetchGamesPage gets one page of games, retrying with the shared retry policy
+func (c *BallDontLieClient) fetchGamesPage(ctx context.Context, pageURL string) (*gamesResponse, error) {
+	resp, err := c.retry.do(ctx, c.httpClient, ProviderBallDontLie, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", c.apiKey)
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("external API (balldontlie) returned %s", resp.Status)
-	}
-
 	var parsed gamesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
 		return nil, err
 	}
-
-	return parsed.Data, nil
+	return &parsed, nil
 }
@@ -0,0 +1,104 @@
+package external
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The Odds API plans have a monthly request quota.  Every response says how much is left, so the latest
+// numbers are kept here for metrics and so auto-import can stop before the quota runs out
+
+var ErrOddsQuotaLow = errors.New("odds API quota is below the configured minimum")
+
+// default for ODDS_API_MIN_REMAINING
+const defaultOddsMinRemaining = 10
+
+// a low quota reading older than this doesn't block requests.  Nothing else refreshes it, so without this one
+// low reading would block auto-import until a restart, even after the monthly reset
+const oddsQuotaStaleAfter = 6 * time.Hour
+
+// OddsQuota is the Odds API usage from the most recent response
+type OddsQuota struct {
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	LastCost  int       `json:"last_cost"` // quota used by the most recent request
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var (
+	oddsQuotaMu sync.RWMutex
+	oddsQuota   *OddsQuota
+)
+
+// CurrentOddsQuota returns the latest known quota, or nil if no Odds API response has been seen since startup
+func CurrentOddsQuota() *OddsQuota {
+	oddsQuotaMu.RLock()
+	defer oddsQuotaMu.RUnlock()
+
+	if oddsQuota == nil {
+		return nil
+	}
+	quota := *oddsQuota
+	return &quota
+}
+
+// recordOddsQuota reads the x-requests-* headers from an Odds API response.
+// Responses without them (mock servers) leave the last known quota alone
+func recordOddsQuota(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("x-requests-remaining"))
+	if err != nil {
+		return
+	}
+	used, _ := strconv.Atoi(header.Get("x-requests-used"))
+	lastCost, _ := strconv.Atoi(header.Get("x-requests-last"))
+
+	oddsQuotaMu.Lock()
+	defer oddsQuotaMu.Unlock()
+
+	oddsQuota = &OddsQuota{
+		Remaining: remaining,
+		Used:      used,
+		LastCost:  lastCost,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// oddsMinRemaining reads ODDS_API_MIN_REMAINING, the quota below which we stop calling the Odds API
+func oddsMinRemaining() (int, error) {
+	raw := os.Getenv("ODDS_API_MIN_REMAINING")
+	if raw == "" {
+		return defaultOddsMinRemaining, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, errors.New("ODDS_API_MIN_REMAINING must be a non-negative number")
+	}
+	return value, nil
+}
+
+// checkOddsQuota refuses a request when the last known quota is below the minimum.
+// An unknown or stale quota (nothing fetched since startup, a reading from an earlier month or older than
+// oddsQuotaStaleAfter) is allowed through; the response will tell us
+func checkOddsQuota(minRemaining int) error {
+	quota := CurrentOddsQuota()
+	if quota == nil || oddsQuotaStale(quota, time.Now()) {
+		return nil
+	}
+	if quota.Remaining < minRemaining {
+		return ErrOddsQuotaLow
+	}
+	return nil
+}
+
+// oddsQuotaStale reports whether a quota reading is too old to trust.  The quota resets monthly (UTC)
+func oddsQuotaStale(quota *OddsQuota, now time.Time) bool {
+	updated, current := quota.UpdatedAt.UTC(), now.UTC()
+	if updated.Year() != current.Year() || updated.Month() != current.Month() {
+		return true
+	}
+	return current.Sub(updated) > oddsQuotaStaleAfter
+}
@@ -0,0 +1,136 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"pawked.com/sendyourpicks/internal/logger"
+)
+
+// Provider neutral game statuses.  These match public.game_status
+const (
+	GameStatusScheduled  = "scheduled"
+	GameStatusInProgress = "in_progress"
+	GameStatusFinal      = "final"
+	GameStatusPostponed  = "postponed"
+	GameStatusCancelled  = "cancelled"
+)
+
+// Provider names for GAMES_PROVIDER and GAMES_PROVIDER_FALLBACK
+const (
+	ProviderBallDontLie = "balldontlie"
+	ProviderESPN        = "espn"
+)
+
+// ProviderGame is a game from any games provider, already translated to our team abbreviations and statuses
+// The json tags are the fixture format (see fixtures.go)
+type ProviderGame struct {
+	ExternalID   int64     `json:"external_id"`
+	KickoffTime  time.Time `json:"kickoff_time"`
+	HomeTeamAbbr string    `json:"home_team_abbr"`
+	AwayTeamAbbr string    `json:"away_team_abbr"`
+	Status       string    `json:"status"` // one of the GameStatus constants
+	HomeScore    *int      `json:"home_score"`
+	AwayScore    *int      `json:"away_score"`
+	Period       *int      `json:"period"` // quarter while in progress (5 and up is overtime)
+	Clock        *string   `json:"clock"`  // game clock while in progress
+}
+
+// GamesProvider fetches the NFL schedule and scores for a week.
+// week is our week number; each provider deals with its own numbering quirks (like the Pro Bowl gap)
+type GamesProvider interface {
+	Name() string
+	FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error)
+}
+
+// NewGamesProvider builds the provider named by GAMES_PROVIDER (default balldontlie).
+// If GAMES_PROVIDER_FALLBACK is set, that provider is tried whenever the primary one fails.
+// PROVIDER_FIXTURES=replay serves recorded fixtures instead, and record saves everything fetched
+func NewGamesProvider() (GamesProvider, error) {
+	mode, err := fixturesMode()
+	if err != nil {
+		return nil, err
+	}
+	if mode == FixturesReplay {
+		return NewReplayProvider(fixturesDir()), nil
+	}
+
+	provider, err := newLiveGamesProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == FixturesRecord {
+		return &recordingProvider{provider: provider, dir: fixturesDir()}, nil
+	}
+	return provider, nil
+}
+
+// newLiveGamesProvider builds the real provider from GAMES_PROVIDER and GAMES_PROVIDER_FALLBACK
+func newLiveGamesProvider() (GamesProvider, error) {
+	primaryName := os.Getenv("GAMES_PROVIDER")
+	if primaryName == "" {
+		primaryName = ProviderBallDontLie
+	}
+
+	primary, err := newGamesProviderByName(primaryName)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackName := os.Getenv("GAMES_PROVIDER_FALLBACK")
+	if fallbackName == "" || strings.EqualFold(fallbackName, primaryName) {
+		return primary, nil
+	}
+
+	fallback, err := newGamesProviderByName(fallbackName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fallbackProvider{primary: primary, fallback: fallback}, nil
+}
+
+func newGamesProviderByName(name string) (GamesProvider, error) {
+	switch strings.ToLower(name) {
+	case ProviderBallDontLie:
+		return NewBallDontLieClient()
+	case ProviderESPN:
+		return NewESPNClient()
+	}
+	return nil, fmt.Errorf("unknown games provider %q", name)
+}
+
+// fallbackProvider tries the primary provider first and the fallback if it errors
+type fallbackProvider struct {
+	primary  GamesProvider
+	fallback GamesProvider
+}
+
+func (p *fallbackProvider) Name() string {
+	return p.primary.Name() + "+" + p.fallback.Name()
+}
+
+func (p *fallbackProvider) FetchWeekGames(ctx context.Context, season int, week int, postseason bool) ([]ProviderGame, error) {
+	games, err := p.primary.FetchWeekGames(ctx, season, week, postseason)
+	if err == nil {
+		return games, nil
+	}
+
+	logger.Warn(
+		"games provider failed, trying fallback",
+		"provider", p.primary.Name(),
+		"fallback", p.fallback.Name(),
+		"error", err,
+	)
+
+	games, fallbackErr := p.fallback.FetchWeekGames(ctx, season, week, postseason)
+	if fallbackErr != nil {
+		return nil, errors.Join(err, fallbackErr)
+	}
+	return games, nil
+}
@@ -0,0 +1,173 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// the provider is down, rate limiting us, or unreachable.  Trying again later may work
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	// the provider rejected the request (bad key, bad parameters).  Trying again won't help
+	ErrProviderBadRequest = errors.New("provider rejected request")
+)
+
+// APIError is returned when a provider request fails.  It wraps ErrProviderUnavailable or
+// ErrProviderBadRequest so callers can tell the two apart with errors.Is
+type APIError struct {
+	Provider   string
+	StatusCode int // 0 when there was no response at all
+	Status     string
+	Body       string // start of the response body, providers usually explain bad requests here
+	Err        error  // network error when there was no response
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("external API (%s) request failed: %v", e.Provider, e.Err)
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("external API (%s) returned %s: %s", e.Provider, e.Status, e.Body)
+	}
+	return fmt.Sprintf("external API (%s) returned %s", e.Provider, e.Status)
+}
+
+func (e *APIError) Unwrap() []error {
+	kind := ErrProviderUnavailable
+	if e.StatusCode != 0 && !retryableStatus(e.StatusCode) {
+		kind = ErrProviderBadRequest
+	}
+	if e.Err != nil {
+		return []error{kind, e.Err}
+	}
+	return []error{kind}
+}
+
+// RetryPolicy controls how provider requests are retried.  Network errors, 429s and 5xxs are retried
+// with exponential backoff and jitter, or after Retry-After when the provider sends one
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first
+	BaseDelay   time.Duration // delay before the first retry, doubled each time
+	MaxDelay    time.Duration // longest we'll wait between attempts, including Retry-After
+}
+
+// DefaultRetryPolicy is shared by every provider client.  Requests often come from a commissioner
+// waiting on a page, so the total wait stays well under a minute
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    15 * time.Second,
+}
+
+// max bytes of an error response body kept in APIError
+const errorBodyLimit = 512
+
+// do sends the request built by newRequest until it succeeds or the policy gives up.  newRequest is called
+// for every attempt so the request body is never reused.  On success the caller closes the response body
+func (p RetryPolicy) do(ctx context.Context, client *http.Client, provider string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	attempts := max(p.MaxAttempts, 1)
+
+	var lastErr *APIError
+	for attempt := 1; attempt <= attempts; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			// a cancelled request isn't the provider's fault
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = &APIError{Provider: provider, Err: err}
+		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		} else {
+			lastErr = &APIError{
+				Provider:   provider,
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Body:       readErrorBody(resp),
+			}
+			if !retryableStatus(resp.StatusCode) {
+				return nil, lastErr
+			}
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		delay := p.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// don't hold a request open for minutes, the provider is effectively down
+				if retryAfter > p.MaxDelay {
+					break
+				}
+				delay = retryAfter
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
+
+// backoff returns the delay before retry number attempt: exponential, capped, with jitter so
+// several clients don't retry in lockstep
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// somewhere between half and all of the delay
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// retryableStatus is true for rate limits and server errors
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// parseRetryAfter reads a Retry-After header, which is either seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// readErrorBody reads the start of a failed response's body and closes it
+func readErrorBody(resp *http.Response) string {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+	return string(body)
+}
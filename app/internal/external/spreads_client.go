@@ -5,9 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"pawked.com/sendyourpicks/internal/logger"
 )
 
 // Odds API structures
@@ -46,59 +52,65 @@ type SpreadInfo struct {
 	AwayTeamAbbr string
 	HomeSpread   float64   // positive means home team is favored by this amount
 	AwaySpread   float64   // negative of HomeSpread
+	Total        *float64  // over/under line, nil unless totals were requested and the bookmaker has one
 	Bookmaker    string    // which bookmaker this spread is from
 	LastUpdate   time.Time
 	CommenceTime time.Time // when the game starts
+
+	// how the spread was chosen and how many bookmakers it came from
+	Method    string  // one of the SpreadMethod constants
+	BookCount int     // bookmakers that had a spread for the game
+	MinSpread float64 // lowest home spread across those bookmakers
+	MaxSpread float64 // highest home spread across those bookmakers
+
+	// every bookmaker's line for the game, for spread history
+	Lines []BookmakerLine
+}
+
+// BookmakerLine is a single bookmaker's line for a game
+type BookmakerLine struct {
+	Key        string
+	Title      string
+	HomeSpread float64
+	Total      *float64
+	LastUpdate time.Time
 }
 
+// provider name for Odds API errors and team aliases
+const ProviderOdds = "odds"
+
 // OddsClient for The Odds API
 type OddsClient struct {
 	httpClient *http.Client
 	apiKey     string
 	baseURL    string
-}
+	retry      RetryPolicy
+
+	// requests are refused once the Odds API quota drops below this, see odds_quota.go
+	minRemaining int
 
-// Team name mapping from Odds API format to your internal abbreviations
-var oddsAPIToAbbreviation = map[string]string{
-	"Arizona Cardinals":     "ARI",
-	"Atlanta Falcons":       "ATL",
-	"Baltimore Ravens":      "BAL",
-	"Buffalo Bills":         "BUF",
-	"Carolina Panthers":     "CAR",
-	"Chicago Bears":         "CHI",
-	"Cincinnati Bengals":    "CIN",
-	"Cleveland Browns":      "CLE",
-	"Dallas Cowboys":        "DAL",
-	"Denver Broncos":        "DEN",
-	"Detroit Lions":         "DET",
-	"Green Bay Packers":     "GB",
-	"Houston Texans":        "HOU",
-	"Indianapolis Colts":    "IND",
-	"Jacksonville Jaguars":  "JAX",
-	"Kansas City Chiefs":    "KC",
-	"Las Vegas Raiders":     "LV",
-	"Los Angeles Chargers":  "LAC",
-	"Los Angeles Rams":      "LAR",
-	"Miami Dolphins":        "MIA",
-	"Minnesota Vikings":     "MIN",
-	"New England Patriots":  "NE",
-	"New Orleans Saints":    "NO",
-	"New York Giants":       "NYG",
-	"New York Jets":         "NYJ",
-	"Philadelphia Eagles":   "PHI",
-	"Pittsburgh Steelers":   "PIT",
-	"San Francisco 49ers":   "SF",
-	"Seattle Seahawks":      "SEA",
-	"Tampa Bay Buccaneers":  "TB",
-	"Tennessee Titans":      "TEN",
-	"Washington Commanders": "WSH",
+	// PROVIDER_FIXTURES record/replay, see fixtures.go
+	fixtures    string
+	fixturesDir string
+	fixtureWeek *fixtureWeek
+
+	// Odds API team names to our abbreviations, from team_aliases.  See WithTeamAliases
+	teamAliases map[string]string
 }
 
+var ErrOddsAPIKeyMissing = errors.New("ODDS_API_KEY not set")
+
 // NewOddsClient creates a new Odds API client
 func NewOddsClient() (*OddsClient, error) {
+	mode, err := fixturesMode()
+	if err != nil {
+		return nil, err
+	}
+
+	// replaying doesn't need a key
 	apiKey := os.Getenv("ODDS_API_KEY")
-	if apiKey == "" {
-		return nil, errors.New("ODDS_API_KEY not set")
+	if apiKey == "" && mode != FixturesReplay {
+		return nil, ErrOddsAPIKeyMissing
 	}
 
 	// Allow override of base URL for testing with mock server
@@ -107,54 +119,79 @@ func NewOddsClient() (*OddsClient, error) {
 		baseURL = "https://api.the-odds-api.com/v4"
 	}
 
+	minRemaining, err := oddsMinRemaining()
+	if err != nil {
+		return nil, err
+	}
+
 	return &OddsClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
+		apiKey:       apiKey,
+		baseURL:      baseURL,
+		retry:        DefaultRetryPolicy,
+		minRemaining: minRemaining,
+		fixtures:     mode,
+		fixturesDir:  fixturesDir(),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}, nil
 }
 
-// FetchSpreads fetches current spreads for NFL games
-// preferredBookmaker can be empty string to use the first available bookmaker
-// Common bookmakers: "draftkings", "fanduel", "betmgm", "caesars"
-func (c *OddsClient) FetchSpreads(ctx context.Context, preferredBookmaker string) ([]SpreadInfo, error) {
-	url := fmt.Sprintf("%s/sports/americanfootball_nfl/odds/?apiKey=%s&regions=us&markets=spreads",
-		c.baseURL, c.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
+// ForWeek returns a copy of the client whose recorded or replayed odds are filed under a week.
+// The Odds API only ever returns upcoming games, so without a week fixtures go to odds/latest.json
+func (c *OddsClient) ForWeek(season int, week int, postseason bool) *OddsClient {
+	weekClient := *c
+	weekClient.fixtureWeek = &fixtureWeek{Season: season, Week: week, Postseason: postseason}
+	return &weekClient
+}
 
-	req.Header.Set("Accept", "application/json")
+// WithTeamAliases returns a copy of the client that maps Odds API team names to our abbreviations with aliases
+// (keyed by lowercased name).  Games with a team that isn't in it are skipped
+func (c *OddsClient) WithTeamAliases(aliases map[string]string) *OddsClient {
+	aliasClient := *c
+	aliasClient.teamAliases = aliases
+	return &aliasClient
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// oddsFixturePath is where this client records and replays odds
+func (c *OddsClient) oddsFixturePath() string {
+	if c.fixtureWeek == nil {
+		return filepath.Join(c.fixturesDir, fixtureKindOdds, fixtureFileOddsLive)
 	}
-	defer resp.Body.Close()
+	return fixturePath(c.fixturesDir, fixtureKindOdds, *c.fixtureWeek)
+}
+
+// How a spread was chosen
+const (
+	SpreadMethodBookmaker = "bookmaker" // one bookmaker's line
+	SpreadMethodMedian    = "median"    // median line across every bookmaker, rounded to the nearest half point
+	SpreadMethodMean      = "mean"      // mean line across every bookmaker, rounded to the nearest half point
+)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("odds API returned %s", resp.Status)
+// IsValidSpreadMethod returns true if the method is one of the SpreadMethod constants
+func IsValidSpreadMethod(method string) bool {
+	switch method {
+	case SpreadMethodBookmaker, SpreadMethodMedian, SpreadMethodMean:
+		return true
 	}
+	return false
+}
 
-	var games []OddsGame
-	if err := json.NewDecoder(resp.Body).Decode(&games); err != nil {
+// FetchSpreads fetches current spreads for NFL games
+// preferredBookmaker can be empty string to use the first available bookmaker
+// Common bookmakers: "draftkings", "fanduel", "betmgm", "caesars"
+// includeTotals also requests the totals (over/under) market.  Each market costs quota so only ask when needed
+func (c *OddsClient) FetchSpreads(ctx context.Context, preferredBookmaker string, includeTotals bool) ([]SpreadInfo, error) {
+	games, err := c.fetchOdds(ctx, includeTotals)
+	if err != nil {
 		return nil, err
 	}
 
 	var spreads []SpreadInfo
 	for _, game := range games {
-		// Convert team names to abbreviations
-		homeAbbr, ok := oddsAPIToAbbreviation[game.HomeTeam]
-		if !ok {
-			return nil, fmt.Errorf("unknown team name: %s", game.HomeTeam)
-		}
-		awayAbbr, ok := oddsAPIToAbbreviation[game.AwayTeam]
+		homeAbbr, awayAbbr, ok := c.oddsGameAbbreviations(game)
 		if !ok {
-			return nil, fmt.Errorf("unknown team name: %s", game.AwayTeam)
+			continue
 		}
 
 		// Find the preferred bookmaker or use the first available
@@ -175,39 +212,257 @@ func (c *OddsClient) FetchSpreads(ctx context.Context, preferredBookmaker string
 			continue // No bookmakers available for this game
 		}
 
-		// Extract spread from markets
-		for _, market := range selectedBookmaker.Markets {
-			if market.Key != "spreads" {
+		line := bookmakerLine(game, *selectedBookmaker)
+
+		// games without a spread are skipped, a total on its own isn't useful
+		if line.homeSpread == nil {
+			continue
+		}
+
+		spreads = append(spreads, SpreadInfo{
+			HomeTeamAbbr: homeAbbr,
+			AwayTeamAbbr: awayAbbr,
+			HomeSpread:   *line.homeSpread,
+			AwaySpread:   line.awaySpread,
+			Total:        line.total,
+			Bookmaker:    selectedBookmaker.Title,
+			LastUpdate:   selectedBookmaker.LastUpdate,
+			CommenceTime: game.CommenceTime,
+			Method:       SpreadMethodBookmaker,
+			BookCount:    1,
+			MinSpread:    *line.homeSpread,
+			MaxSpread:    *line.homeSpread,
+			Lines:        allBookmakerLines(game),
+		})
+	}
+
+	return spreads, nil
+}
+
+// FetchConsensusSpreads fetches current spreads for NFL games and combines every bookmaker's line into one,
+// using the median or mean (method) rounded to the nearest half point.  Totals are combined the same way
+func (c *OddsClient) FetchConsensusSpreads(ctx context.Context, method string, includeTotals bool) ([]SpreadInfo, error) {
+	if method != SpreadMethodMedian && method != SpreadMethodMean {
+		return nil, fmt.Errorf("unknown consensus method: %s", method)
+	}
+
+	games, err := c.fetchOdds(ctx, includeTotals)
+	if err != nil {
+		return nil, err
+	}
+
+	var spreads []SpreadInfo
+	for _, game := range games {
+		homeAbbr, awayAbbr, ok := c.oddsGameAbbreviations(game)
+		if !ok {
+			continue
+		}
+
+		var homeSpreads, totals []float64
+		var lastUpdate time.Time
+		for _, bookmaker := range game.Bookmakers {
+			line := bookmakerLine(game, bookmaker)
+			if line.homeSpread == nil {
 				continue
 			}
+			homeSpreads = append(homeSpreads, *line.homeSpread)
+			if line.total != nil {
+				totals = append(totals, *line.total)
+			}
+			if bookmaker.LastUpdate.After(lastUpdate) {
+				lastUpdate = bookmaker.LastUpdate
+			}
+		}
+
+		// games without a spread are skipped, a total on its own isn't useful
+		if len(homeSpreads) == 0 {
+			continue
+		}
+
+		homeSpread := roundToHalf(combineLines(homeSpreads, method))
+		minSpread, maxSpread := lineRange(homeSpreads)
+
+		var total *float64
+		if len(totals) > 0 {
+			value := roundToHalf(combineLines(totals, method))
+			total = &value
+		}
+
+		spreads = append(spreads, SpreadInfo{
+			HomeTeamAbbr: homeAbbr,
+			AwayTeamAbbr: awayAbbr,
+			HomeSpread:   homeSpread,
+			AwaySpread:   -homeSpread,
+			Total:        total,
+			Bookmaker:    "consensus",
+			LastUpdate:   lastUpdate,
+			CommenceTime: game.CommenceTime,
+			Method:       method,
+			BookCount:    len(homeSpreads),
+			MinSpread:    minSpread,
+			MaxSpread:    maxSpread,
+			Lines:        allBookmakerLines(game),
+		})
+	}
+
+	return spreads, nil
+}
+
+// fetchOdds gets the raw odds for every upcoming NFL game
+func (c *OddsClient) fetchOdds(ctx context.Context, includeTotals bool) ([]OddsGame, error) {
+	if c.fixtures == FixturesReplay {
+		var games []OddsGame
+		if err := readFixture(c.oddsFixturePath(), &games); err != nil {
+			return nil, err
+		}
+		return games, nil
+	}
+
+	// don't burn the last of the monthly quota
+	if err := checkOddsQuota(c.minRemaining); err != nil {
+		return nil, err
+	}
+
+	markets := "spreads"
+	if includeTotals {
+		markets = "spreads,totals"
+	}
+
+	url := fmt.Sprintf("%s/sports/americanfootball_nfl/odds/?apiKey=%s&regions=us&markets=%s",
+		c.baseURL, c.apiKey, markets)
+
+	resp, err := c.retry.do(ctx, c.httpClient, ProviderOdds, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	recordOddsQuota(resp.Header)
 
+	var games []OddsGame
+	if err := json.NewDecoder(resp.Body).Decode(&games); err != nil {
+		return nil, err
+	}
+
+	// a failed recording shouldn't fail the import
+	if c.fixtures == FixturesRecord {
+		path := c.oddsFixturePath()
+		if err := writeFixture(path, games); err != nil {
+			logger.Warn("failed to record odds fixture", "path", path, "error", err)
+		}
+	}
+
+	return games, nil
+}
+
+// oddsGameAbbreviations converts the game's team names to abbreviations with the team aliases.
+// ok is false when either team has no alias
+func (c *OddsClient) oddsGameAbbreviations(game OddsGame) (string, string, bool) {
+	homeAbbr, homeOK := c.teamAliases[strings.ToLower(game.HomeTeam)]
+	awayAbbr, awayOK := c.teamAliases[strings.ToLower(game.AwayTeam)]
+	if !homeOK || !awayOK {
+		logger.Warn(
+			"skipping odds game with unknown team name, add a team alias for it",
+			"home_team", game.HomeTeam,
+			"away_team", game.AwayTeam,
+		)
+		return "", "", false
+	}
+	return homeAbbr, awayAbbr, true
+}
+
+// one bookmaker's lines for a game.  homeSpread is nil if the bookmaker has no spread
+type oddsLine struct {
+	homeSpread *float64
+	awaySpread float64
+	total      *float64
+}
+
+// bookmakerLine extracts the spread (and total) from a bookmaker's markets
+func bookmakerLine(game OddsGame, bookmaker OddsBookmaker) oddsLine {
+	var line oddsLine
+	for _, market := range bookmaker.Markets {
+		switch market.Key {
+		case "spreads":
 			// Find home and away spreads
-			var homeSpread, awaySpread float64
+			var homeSpread float64
 			for _, outcome := range market.Outcomes {
 				if outcome.Name == game.HomeTeam {
 					homeSpread = outcome.Point
 				} else if outcome.Name == game.AwayTeam {
-					awaySpread = outcome.Point
+					line.awaySpread = outcome.Point
 				}
 			}
+			line.homeSpread = &homeSpread
+		case "totals":
+			// Over and Under outcomes carry the same point value
+			for _, outcome := range market.Outcomes {
+				if outcome.Name == "Over" {
+					point := outcome.Point
+					line.total = &point
+				}
+			}
+		}
+	}
+	return line
+}
 
-			spreads = append(spreads, SpreadInfo{
-				HomeTeamAbbr: homeAbbr,
-				AwayTeamAbbr: awayAbbr,
-				HomeSpread:   homeSpread,
-				AwaySpread:   awaySpread,
-				Bookmaker:    selectedBookmaker.Title,
-				LastUpdate:   selectedBookmaker.LastUpdate,
-				CommenceTime: game.CommenceTime,
-			})
+// allBookmakerLines returns the line from every bookmaker that has a spread for the game
+func allBookmakerLines(game OddsGame) []BookmakerLine {
+	lines := make([]BookmakerLine, 0, len(game.Bookmakers))
+	for _, bookmaker := range game.Bookmakers {
+		line := bookmakerLine(game, bookmaker)
+		if line.homeSpread == nil {
+			continue
 		}
+		lines = append(lines, BookmakerLine{
+			Key:        bookmaker.Key,
+			Title:      bookmaker.Title,
+			HomeSpread: *line.homeSpread,
+			Total:      line.total,
+			LastUpdate: bookmaker.LastUpdate,
+		})
 	}
+	return lines
+}
 
-	return spreads, nil
+// combineLines returns the median or mean of the lines
+func combineLines(lines []float64, method string) float64 {
+	if method == SpreadMethodMean {
+		sum := 0.0
+		for _, line := range lines {
+			sum += line
+		}
+		return sum / float64(len(lines))
+	}
+
+	sorted := append([]float64(nil), lines...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// lineRange returns the lowest and highest line
+func lineRange(lines []float64) (float64, float64) {
+	minLine, maxLine := lines[0], lines[0]
+	for _, line := range lines[1:] {
+		minLine = math.Min(minLine, line)
+		maxLine = math.Max(maxLine, line)
+	}
+	return minLine, maxLine
 }
 
-// GetTeamAbbreviation converts an Odds API team name to your internal abbreviation
-func GetTeamAbbreviation(oddsAPITeamName string) (string, bool) {
-	abbr, ok := oddsAPIToAbbreviation[oddsAPITeamName]
-	return abbr, ok
+// roundToHalf rounds to the nearest half point
+func roundToHalf(value float64) float64 {
+	return math.Round(value*2) / 2
 }
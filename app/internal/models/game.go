@@ -6,7 +6,7 @@ import (
 
 type Game struct {
 	ID             string    `json:"id" db:"id"`
-	ExternalGameID string    `json:"external_game_id" db:"external_game_id"`
+	ExternalGameID *string   `json:"external_game_id" db:"external_game_id"` // nil for games added by hand
 	SeasonID       string    `json:"season_id" db:"season_id"`
 	WeekID         string    `json:"week_id" db:"week_id"`
 	HomeTeamID     string    `json:"home_team_id" db:"home_team_id"`
@@ -14,6 +14,7 @@ type Game struct {
 	HomeScore      *int      `json:"home_score" db:"home_score"`
 	AwayScore      *int      `json:"away_score" db:"away_score"`
 	HomeSpread     *float64  `json:"home_spread" db:"home_spread"`
+	Total          *float64  `json:"total" db:"total"` // over/under line, only used when the week has totals enabled
 	KickoffTime    time.Time `json:"kickoff_time" db:"kickoff_time"`
 	NeutralSite    bool      `json:"neutral_site" db:"neutral_site"`
 	Status         string    `json:"status" db:"status"`
@@ -23,6 +24,15 @@ type Game struct {
 	HomeTeamAbbr   string    `json:"home_team_abbr" db:"home_team_abbr"`
 	AwayTeamAbbr   string    `json:"away_team_abbr" db:"away_team_abbr"`
 
+	// live progress for games in progress
+	Period *int    `json:"period" db:"period"`
+	Clock  *string `json:"clock" db:"clock"`
+
+	// voided games don't count for anyone's picks
+	VoidedAt   *time.Time `json:"voided_at" db:"voided_at"`
+	VoidReason *string    `json:"void_reason" db:"void_reason"`
+	VoidedBy   *string    `json:"voided_by,omitempty" db:"voided_by"`
+
 	// names are populated with JOIN in query when necessary, not from the games table
 	HomeTeamName    string `json:"home_team_name,omitempty" db:"home_team_name"`
 	HomeTeamCity    string `json:"home_team_city,omitempty" db:"home_team_city"`
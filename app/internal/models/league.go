@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+type League struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy *string   `json:"created_by" db:"created_by"` // nil for the default league
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// the requesting user's role, populated with JOIN when listing a user's leagues
+	Role string `json:"role,omitempty" db:"role"`
+}
+
+// LeagueMember is a user in a league with their league role, including profile info
+type LeagueMember struct {
+	LeagueID  string    `json:"league_id" db:"league_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	Role      string    `json:"role" db:"role"` // owner, commissioner or member
+	JoinedAt  time.Time `json:"joined_at" db:"joined_at"`
+	Username  *string   `json:"username" db:"username"`
+	AvatarURL *string   `json:"avatar_url" db:"avatar_url"`
+}
+
+// LeagueSettings are a league's competition timezone and the rules its new seasons start with.
+// nil fields use the global Settings
+type LeagueSettings struct {
+	LeagueID string `db:"league_id" json:"league_id"`
+
+	PickCutoffMinutes          *int    `db:"pick_cutoff_minutes" json:"pick_cutoff_minutes"`
+	AllowPickEdits             *bool   `db:"allow_pick_edits" json:"allow_pick_edits"`
+	PointsPerCorrectPick       *int    `db:"points_per_correct_pick" json:"points_per_correct_pick"`
+	CompetitionTimezone        *string `db:"competition_timezone" json:"competition_timezone"`
+	AllowCommissionerOverrides *bool   `db:"allow_commissioner_overrides" json:"allow_commissioner_overrides"`
+	AllowPicksAfterKickoff     *bool   `db:"allow_picks_after_kickoff" json:"allow_picks_after_kickoff"`
+	PickLockRule               *string `db:"pick_lock_rule" json:"pick_lock_rule"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
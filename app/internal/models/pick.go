@@ -15,6 +15,16 @@ type Pick struct {
 	IsCorrect      *bool      `json:"is_correct" db:"is_correct"`
 	CalculatedAt   *time.Time `json:"calculated_at" db:"calculated_at"`
 	UserLockedAt   *time.Time `json:"user_locked_at" db:"user_locked_at"`
+	Confidence     *int       `json:"confidence" db:"confidence"` // only used in confidence-mode seasons
+	IsPush         bool       `json:"is_push" db:"is_push"`       // game landed exactly on the spread
+	TotalPick      *string    `json:"total_pick" db:"total_pick"` // over or under, only used when the week has totals enabled
+	TotalIsCorrect *bool      `json:"total_is_correct" db:"total_is_correct"`
+	TotalIsPush    bool       `json:"total_is_push" db:"total_is_push"`
+
+	// set when a commissioner set or cleared the pick for the user
+	OverriddenBy   *string    `json:"overridden_by" db:"overridden_by"`
+	OverriddenAt   *time.Time `json:"overridden_at" db:"overridden_at"`
+	OverrideReason *string    `json:"override_reason" db:"override_reason"`
 }
 
 type PickSummary struct {
@@ -6,6 +6,7 @@ import (
 
 type Season struct {
 	ID            string    `json:"id" db:"id"`                           // year's ULID
+	LeagueID      string    `json:"league_id" db:"league_id"`             // the league running the season
 	Year          int       `json:"year" db:"year"`                       // which year it's for
 	IsActive      bool      `json:"is_active" db:"is_active"`             // to close the season at the end
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`           // when the season was created
@@ -13,6 +14,12 @@ type Season struct {
 	CreatedBy     string    `json:"created_by" db:"created_by"`           // which user created the season
 	NumberOfWeeks int       `json:"number_of_weeks" db:"number_of_weeks"` // how many weeks are in the season
 	IsPostseason  bool      `json:"is_postseason" db:"is_postseason"`     // postseason flag
+	ScoringMode   string    `json:"scoring_mode" db:"scoring_mode"`       // standard or confidence
+	SeasonType    string    `json:"season_type" db:"season_type"`         // pickem or survivor
+	PushPolicy    string    `json:"push_policy" db:"push_policy"`         // how pushes are scored: loss, win, half or void
+
+	// survivor seasons only: grade picks against the spread (true) or straight-up (false)
+	SurvivorUseSpread bool `json:"survivor_use_spread" db:"survivor_use_spread"`
 }
 
 // SeasonParticipant represents a row in the season_participants table.
@@ -21,6 +28,10 @@ type SeasonParticipant struct {
 	SeasonID string    `json:"season_id" db:"season_id"` // the season being participated in
 	UserID   string    `json:"user_id" db:"user_id"`     // the user participating
 	JoinedAt time.Time `json:"joined_at" db:"joined_at"` // when the user was added to the season
+
+	// survivor seasons only.  Both are NULL while the user is still alive
+	EliminatedWeekID *string    `json:"eliminated_week_id" db:"eliminated_week_id"`
+	EliminatedAt     *time.Time `json:"eliminated_at" db:"eliminated_at"`
 }
 
 // Participant is an enriched view of a season participant, including profile info.
@@ -33,3 +44,15 @@ type Participant struct {
 	AvatarURL *string   `json:"avatar_url" db:"avatar_url"` // profile picture URL (nullable)
 	JoinedAt  time.Time `json:"joined_at" db:"joined_at"`   // when added to this season
 }
+
+// SeasonRole is a user's role in a season, including profile info.
+// owner and co_commissioner can manage the season; participant and spectator can't
+type SeasonRole struct {
+	SeasonID   string    `json:"season_id" db:"season_id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	Role       string    `json:"role" db:"role"`               // owner, co_commissioner, participant or spectator
+	AssignedBy *string   `json:"assigned_by" db:"assigned_by"` // nil for roles from the backfill
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	Username   *string   `json:"username" db:"username"`
+}
@@ -12,6 +12,22 @@ type Settings struct {
 	AllowCommissionerOverrides bool   `db:"allow_commissioner_overrides" json:"allow_commissioner_overrides"`
 	AllowPicksAfterKickoff     bool   `db:"allow_picks_after_kickoff" json:"allow_picks_after_kickoff"`
 	DebugMode                  bool   `db:"debug_mode" json:"debug_mode"`
+	PickLockRule               string `db:"pick_lock_rule" json:"pick_lock_rule"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// SeasonSettings are the scoring and pick rules a season plays by, copied from Settings when it's created
+type SeasonSettings struct {
+	SeasonID string `db:"season_id" json:"season_id"`
+
+	PickCutoffMinutes          int    `db:"pick_cutoff_minutes" json:"pick_cutoff_minutes"`
+	AllowPickEdits             bool   `db:"allow_pick_edits" json:"allow_pick_edits"`
+	PointsPerCorrectPick       int    `db:"points_per_correct_pick" json:"points_per_correct_pick"`
+	AllowCommissionerOverrides bool   `db:"allow_commissioner_overrides" json:"allow_commissioner_overrides"`
+	AllowPicksAfterKickoff     bool   `db:"allow_picks_after_kickoff" json:"allow_picks_after_kickoff"`
+	PickLockRule               string `db:"pick_lock_rule" json:"pick_lock_rule"`
 
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
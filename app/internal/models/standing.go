@@ -8,9 +8,12 @@ type WeekResult struct {
 	ID         string    `json:"id" db:"id"`
 	UserID     string    `json:"user_id" db:"user_id"`
 	WeekID     string    `json:"week_id" db:"week_id"`
-	Points     int       `json:"points" db:"points"`
+	Points     float64   `json:"points" db:"points"`
 	Rank       int       `json:"rank" db:"rank"`
 	ComputedAt time.Time `json:"computed_at" db:"computed_at"`
+
+	// distance from the tiebreaker game's combined score, NULL if the user made no prediction
+	TiebreakerDiff *int `json:"tiebreaker_diff" db:"tiebreaker_diff"`
 }
 
 type SeasonStanding struct {
@@ -18,7 +21,7 @@ type SeasonStanding struct {
 	UserID     string    `json:"user_id" db:"user_id"`
 	SeasonID   string    `json:"season_id" db:"season_id"`
 	WeekID     string    `json:"week_id" db:"week_id"`
-	Points     int       `json:"points" db:"points"`
+	Points     float64   `json:"points" db:"points"`
 	Rank       int       `json:"rank" db:"rank"`
 	ComputedAt time.Time `json:"computed_at" db:"computed_at"`
 }
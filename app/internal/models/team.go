@@ -12,3 +12,16 @@ type Team struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// TeamAlias maps a provider's name for a team to our team
+type TeamAlias struct {
+	ID        string    `json:"id" db:"id"`
+	Provider  string    `json:"provider" db:"provider"` // odds, balldontlie, espn, or any
+	Alias     string    `json:"alias" db:"alias"`
+	TeamID    string    `json:"team_id" db:"team_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// populated with JOIN in query, not from the team_aliases table
+	TeamAbbreviation string `json:"team_abbreviation,omitempty" db:"team_abbreviation"`
+}
@@ -17,6 +17,9 @@ type Week struct {
 	ActivatedAt  *time.Time `json:"activated_at" db:"activated_at"`
 	ClosedAt *string `json:"closed_at" db:"closed_at"`
 
+	// over/under picks are turned on for this week
+	TotalsEnabled bool `json:"totals_enabled" db:"totals_enabled"`
+
 	// Games is optional and not in the database
 	Games []Game `json:"games"`
 }
@@ -28,4 +31,10 @@ type WeekWithYear struct {
 	Status       string `json:"status" db:"status"`
 	Year         int    `json:"year" db:"year"`
 	IsPostseason bool   `json:"is_postseason" db:"is_postseason"`
+	ScoringMode  string `json:"scoring_mode" db:"scoring_mode"`
+	SeasonType   string `json:"season_type" db:"season_type"`
+	PushPolicy   string `json:"push_policy" db:"push_policy"`
+
+	TotalsEnabled     bool `json:"totals_enabled" db:"totals_enabled"`
+	SurvivorUseSpread bool `json:"survivor_use_spread" db:"survivor_use_spread"`
 }
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// What AdvanceSeason ended up doing
+const (
+	AdvanceActionSeasonComplete       = "season_complete"
+	AdvanceActionManualActionRequired = "manual_action_required"
+	AdvanceActionWaitingForGames      = "waiting_for_games"
+	AdvanceActionWeekAdvanced         = "week_advanced"
+)
+
+type AdvanceSeasonResult struct {
+	Action string
+	WeekID string
+	Status string
+}
+
+// AdvanceSeason runs the season's current week through AdvanceWeekState, creating the next week
+// first if every existing week is final.  Used by the commissioner button and the background scheduler.
+// Manual action and waiting for games are normal stopping points so they're returned as actions, not errors.
+func AdvanceSeason(ctx context.Context, db *sqlx.DB, seasonID string, actingUserID string) (*AdvanceSeasonResult, error) {
+
+	// first we check if there are any nonfinal weeks
+	var week models.Week
+	err := db.Get(&week, `
+		SELECT *
+		FROM weeks
+		WHERE season_id = $1
+			AND status != 'final'
+		LIMIT 1
+	`, seasonID)
+
+	// an error of no rows would mean there are no final weeks
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// No active week so we create one
+		logger.Info("No active week.  Creating next week", "season_id", seasonID)
+
+		newWeek, err := CreateNextWeekForSeason(db, seasonID, actingUserID)
+		if errors.Is(err, ErrSeasonComplete) {
+			return &AdvanceSeasonResult{Action: AdvanceActionSeasonComplete}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// set week to the newly created week
+		week = *newWeek
+	}
+
+	// A non-final week exists so we advance it
+	logger.Debug("A non-final week exists.  Calling AdvanceWeekState", "week_number", week.Number)
+
+	action := AdvanceActionWeekAdvanced
+	if err := AdvanceWeekState(ctx, db, &week, actingUserID); err != nil {
+		switch {
+		// not really an error, just needs commissioner input
+		case errors.Is(err, ErrManualActionRequired):
+			action = AdvanceActionManualActionRequired
+		case errors.Is(err, ErrWaitingForGames):
+			action = AdvanceActionWaitingForGames
+		default:
+			return nil, err
+		}
+	}
+
+	// Reload week to get updated status
+	if err := db.Get(&week, `SELECT * FROM weeks WHERE id = $1`, week.ID); err != nil {
+		return nil, err
+	}
+
+	return &AdvanceSeasonResult{
+		Action: action,
+		WeekID: week.ID,
+		Status: week.Status,
+	}, nil
+}
+
+// GetActiveSeasonIDs returns the IDs of every active season (one per season type in each league)
+func GetActiveSeasonIDs(db *sqlx.DB) ([]string, error) {
+	var seasonIDs []string
+	if err := db.Select(&seasonIDs, `SELECT id FROM public.seasons WHERE is_active = true ORDER BY season_type`); err != nil {
+		return nil, err
+	}
+	return seasonIDs, nil
+}
+
+// GetActiveWeekIDs returns the IDs of the active weeks in every active season
+func GetActiveWeekIDs(db *sqlx.DB) ([]string, error) {
+	var weekIDs []string
+	err := db.Select(&weekIDs, `
+		SELECT w.id
+		FROM public.weeks w
+		JOIN public.seasons s ON s.id = w.season_id
+		WHERE s.is_active = true
+		AND w.status = 'active'
+		ORDER BY s.season_type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return weekIDs, nil
+}
+
+// IsGameWindow returns true if any game in an active week is in progress, or kicked off recently
+// enough that it could still be going.  Used by the scheduler to poll faster on game days
+func IsGameWindow(db *sqlx.DB, now time.Time) (bool, error) {
+	var inWindow bool
+	err := db.Get(&inWindow, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.games g
+			JOIN public.weeks w ON w.id = g.week_id
+			JOIN public.seasons s ON s.id = w.season_id
+			WHERE s.is_active = true
+			AND w.status = 'active'
+			AND g.status <> 'final'
+			AND g.kickoff_time <= $1
+			AND g.kickoff_time > $1 - INTERVAL '6 hours'
+		)
+	`, now)
+	if err != nil {
+		return false, err
+	}
+	return inWindow, nil
+}
@@ -27,6 +27,23 @@ const (
 	StatusFinal                  = "final"
 )
 
+// where each status sits in the state machine, for comparing how far along a week is
+var weekStatusOrder = map[string]int{
+	StatusDraft:                  0,
+	StatusGamesImported:          1,
+	StatusSpreadsSet:             2,
+	StatusActive:                 3,
+	StatusPlayed:                 4,
+	StatusPicksResultsCalculated: 5,
+	StatusScored:                 6,
+	StatusFinal:                  7,
+}
+
+// isWeekPast returns true if the week status has reached (or passed) the given status
+func isWeekPast(status string, reached string) bool {
+	return weekStatusOrder[status] >= weekStatusOrder[reached]
+}
+
 var (
 	ErrManualActionRequired = errors.New("manual action required")
 	ErrWeekAlreadyFinal     = errors.New("week is already final")
@@ -104,6 +121,17 @@ func AdvanceWeekState(ctx context.Context, db *sqlx.DB, week *models.Week, actin
 
 		// PickResults have been calculated from the games
 		case StatusPicksResultsCalculated:
+			// Automated: Knock out survivors who lost (no-op for pick'em seasons).
+			// Runs here rather than after CalculatePickResults so a failed run is retried on the next call
+			logger.Debug("AdvanceWeekState: applying survivor eliminations for week", "week_number", week.Number)
+
+			elim, err := ApplySurvivorEliminations(ctx, db, week.ID)
+			if err != nil {
+				return err
+			}
+
+			logger.Debug("AdvanceWeekState: survivor eliminations applied", "users_eliminated", elim.UsersEliminated)
+
 			// Automated: Calculate week points and rankings
 			logger.Debug("AdvanceWeekState: calculating week points for week", "week_number", week.Number)
 
@@ -251,13 +279,13 @@ func ImportGamesForWeek(ctx context.Context, db *sqlx.DB, weekID string, actingU
 		return nil, ErrInvalidWeekState
 	}
 
-	// load the external client
-	client, err := external.NewBallDontLieClient()
+	// load the configured games provider
+	provider, err := external.NewGamesProvider()
 	if err != nil {
 		return nil, err
 	}
 
-	externalGames, err := client.FetchGames(
+	externalGames, err := provider.FetchWeekGames(
 		ctx,
 		week.Year,
 		week.Number,
@@ -267,8 +295,13 @@ func ImportGamesForWeek(ctx context.Context, db *sqlx.DB, weekID string, actingU
 		return nil, err
 	}
 
+	// providers don't always use our abbreviations
+	if err := resolveTeamAbbreviations(db, provider.Name(), externalGames); err != nil {
+		return nil, err
+	}
+
 	if len(externalGames) == 0 {
-		return nil, fmt.Errorf("external API returned 0 games for week %d (year %d, postseason=%v)", week.Number, week.Year, week.IsPostseason)
+		return nil, fmt.Errorf("games provider %s returned 0 games for week %d (year %d, postseason=%v)", provider.Name(), week.Number, week.Year, week.IsPostseason)
 	}
 
 	// games from the external api validated and using the internal IDs and Abbrs
@@ -285,24 +318,24 @@ func ImportGamesForWeek(ctx context.Context, db *sqlx.DB, weekID string, actingU
 
 	// go through the external games and turn them into validatedGames
 	for _, g := range externalGames {
-		homeTeamID, err := GetTeamIDByAbbreviation(db, g.HomeTeam.Abbreviation)
+		homeTeamID, err := GetTeamIDByAbbreviation(db, provider.Name(), g.HomeTeamAbbr)
 		if err != nil {
 			return nil, err
 		}
 
-		awayTeamID, err := GetTeamIDByAbbreviation(db, g.AwayTeam.Abbreviation)
+		awayTeamID, err := GetTeamIDByAbbreviation(db, provider.Name(), g.AwayTeamAbbr)
 		if err != nil {
 			return nil, err
 		}
 
 		// add it to the list
 		validated = append(validated, validatedGame{
-			ExternalID:   g.ID,
-			KickoffTime:  g.Date,
+			ExternalID:   g.ExternalID,
+			KickoffTime:  g.KickoffTime,
 			HomeTeamID:   homeTeamID,
 			AwayTeamID:   awayTeamID,
-			HomeTeamAbbr: g.HomeTeam.Abbreviation,
-			AwayTeamAbbr: g.AwayTeam.Abbreviation,
+			HomeTeamAbbr: g.HomeTeamAbbr,
+			AwayTeamAbbr: g.AwayTeamAbbr,
 		})
 	}
 
@@ -313,7 +346,8 @@ func ImportGamesForWeek(ctx context.Context, db *sqlx.DB, weekID string, actingU
 	}
 	defer tx.Rollback()
 
-	// always make sure there are no games whenever we import a week
+	// always make sure there are no games whenever we import a week.  Only draft weeks get here, weeks that
+	// already have spreads or picks are updated with RefreshWeekSchedule instead
 	if _, err := tx.Exec(
 		`DELETE FROM public.games WHERE week_id = $1`,
 		weekID,
@@ -396,7 +430,25 @@ var (
 	ErrWeekNotActive = errors.New("week not in active state")
 )
 
-// ImportScoresForWeek fetches scores from the external API and updates games.
+// matchProviderGame finds our game for a provider game.  The external ID is preferred, but the matchup is
+// used too so scores still line up when the week was imported from a different provider
+func matchProviderGame(tx *sqlx.Tx, weekID string, game external.ProviderGame) (string, error) {
+	var gameID string
+	err := tx.Get(&gameID, `
+		SELECT id
+		FROM public.games
+		WHERE week_id = $1
+		AND (
+			external_game_id = $2
+			OR (home_team_abbr = $3 AND away_team_abbr = $4)
+		)
+		ORDER BY (external_game_id = $2) DESC NULLS LAST
+		LIMIT 1
+	`, weekID, game.ExternalID, game.HomeTeamAbbr, game.AwayTeamAbbr)
+	return gameID, err
+}
+
+// ImportScoresForWeek fetches scores from the games provider and updates games.
 // If all games become final, it transitions the week to "played" status.
 func ImportScoresForWeek(ctx context.Context, db *sqlx.DB, weekID string) (*ImportScoresResult, error) {
 
@@ -412,17 +464,22 @@ func ImportScoresForWeek(ctx context.Context, db *sqlx.DB, weekID string) (*Impo
 
 	logger.Debug("ImportScoresForWeek: fetching scores", "week_number", week.Number, "postseason", week.IsPostseason, "year", week.Year)
 
-	// start up the external nfl client and get the games
-	client, err := external.NewBallDontLieClient()
+	// start up the games provider and get the games
+	provider, err := external.NewGamesProvider()
 	if err != nil {
 		return nil, err
 	}
 
-	externalGames, err := client.FetchGames(ctx, week.Year, week.Number, week.IsPostseason)
+	externalGames, err := provider.FetchWeekGames(ctx, week.Year, week.Number, week.IsPostseason)
 	if err != nil {
 		return nil, err
 	}
 
+	// matching by matchup needs our abbreviations
+	if err := resolveTeamAbbreviations(db, provider.Name(), externalGames); err != nil {
+		return nil, err
+	}
+
 	// set up the transaction
 	tx, err := db.Beginx()
 	if err != nil {
@@ -441,40 +498,81 @@ func ImportScoresForWeek(ctx context.Context, db *sqlx.DB, weekID string) (*Impo
 	var unmatchedGameIDs []int64
 
 	for _, externalGame := range externalGames {
-		// only import final games for now (in the future i'd like to import game scores as they go, but that's kind of hard to do in the offseason)
-		if externalGame.Status != "Final" && externalGame.Status != "Final/OT" {
+		// scheduled games are skipped, everything else gets its status (and scores when there are some)
+		if externalGame.Status == external.GameStatusScheduled {
 			continue
 		}
 
-		result, err := tx.Exec(`
-			UPDATE public.games
-			SET
-				home_score = $1,
-				away_score = $2,
-				status = 'final',
-				updated_at = NOW()
-			WHERE external_game_id = $3
-			AND week_id = $4
-		`, externalGame.HomeTeamScore, externalGame.AwayTeamScore, externalGame.ID, weekID)
+		gameID, err := matchProviderGame(tx, weekID, externalGame)
+		if errors.Is(err, sql.ErrNoRows) {
+			// this external game didn't match any game in our database
+			unmatchedGameIDs = append(unmatchedGameIDs, externalGame.ExternalID)
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		rows, _ := result.RowsAffected()
-		if rows == 0 {
-			// this external game didn't match any game in our database
-			unmatchedGameIDs = append(unmatchedGameIDs, externalGame.ID)
-		} else {
-			updated += int(rows)
+		switch externalGame.Status {
+		case external.GameStatusFinal:
+			_, err = tx.Exec(`
+				UPDATE public.games
+				SET
+					home_score = $1,
+					away_score = $2,
+					status = 'final',
+					clock = NULL,
+					updated_at = NOW()
+				WHERE id = $3
+			`, externalGame.HomeScore, externalGame.AwayScore, gameID)
+
+		// live scores so the week can show provisional results.  Nothing is graded until the game is final
+		case external.GameStatusInProgress:
+			_, err = tx.Exec(`
+				UPDATE public.games
+				SET
+					home_score = COALESCE($1, home_score),
+					away_score = COALESCE($2, away_score),
+					period = COALESCE($3, period),
+					clock = $4,
+					status = 'in_progress',
+					updated_at = NOW()
+				WHERE id = $5
+			`, externalGame.HomeScore, externalGame.AwayScore, externalGame.Period, externalGame.Clock, gameID)
+
+		// postponed games hold up the week until they're played or a commissioner voids them
+		case external.GameStatusPostponed:
+			_, err = tx.Exec(`
+				UPDATE public.games
+				SET status = 'postponed', updated_at = NOW()
+				WHERE id = $1
+			`, gameID)
+
+		// cancelled games are never going to be played so they're voided right away
+		case external.GameStatusCancelled:
+			_, err = tx.Exec(`
+				UPDATE public.games
+				SET
+					status = 'cancelled',
+					voided_at = COALESCE(voided_at, NOW()),
+					void_reason = COALESCE(void_reason, $1),
+					updated_at = NOW()
+				WHERE id = $2
+			`, VoidReasonCancelled, gameID)
 		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated++
 	}
 
-	// check if all games are now final
+	// check if all games are now final.  Voided games don't count
 	var gamesNotFinal int
 	err = tx.Get(&gamesNotFinal, `
 		SELECT COUNT(*)
 		FROM public.games
-		WHERE week_id = $1 AND status <> 'final'
+		WHERE week_id = $1 AND status <> 'final' AND voided_at IS NULL
 	`, weekID)
 	if err != nil {
 		return nil, err
@@ -533,7 +631,11 @@ var (
 )
 
 // WinningTeamByGame returns the winning team ID based on score + spread, and nil for ties.
+// Also nil when the game is missing its scores or spread (callers treat a game without a spread as void)
 func WinningTeamByGame(game models.Game) *string {
+	if game.HomeScore == nil || game.AwayScore == nil || game.HomeSpread == nil {
+		return nil
+	}
 
 	// hometeam score + the spread (which can be minus)
 	adjustedHomeScore := float64(*game.HomeScore) + *game.HomeSpread
@@ -551,41 +653,115 @@ func WinningTeamByGame(game models.Game) *string {
 	return nil
 }
 
+// TotalResultByGame returns "over" or "under" based on the combined score against the total, and nil for a push
+// or a game without scores or a total.
+func TotalResultByGame(game models.Game) *string {
+	if game.HomeScore == nil || game.AwayScore == nil || game.Total == nil {
+		return nil
+	}
+
+	combined := float64(*game.HomeScore + *game.AwayScore)
+
+	result := TotalPickOver
+	if combined < *game.Total {
+		result = TotalPickUnder
+	} else if combined == *game.Total {
+		return nil
+	}
+	return &result
+}
+
+// gradePick compares a pick to the winning side.  A nil winner is a push and gets graded by the push policy:
+// loss and win set is_correct, half and void leave it NULL for CalculateWeekPoints to handle.
+// No pick at all is never correct or a push.
+func gradePick(picked *string, winner *string, pushPolicy string) (isCorrect *bool, isPush bool) {
+	if picked == nil {
+		return nil, false
+	}
+
+	if winner != nil {
+		// pointers are fun
+		value := *picked == *winner
+		return &value, false
+	}
+
+	switch pushPolicy {
+	case PushPolicyLoss:
+		value := false
+		return &value, true
+	case PushPolicyWin:
+		value := true
+		return &value, true
+	}
+	return nil, true
+}
+
 // calculates if a pick was correct or not
 func CalculatePickResults(ctx context.Context, db *sqlx.DB, weekID string) (*CalculatePickResultsResult, error) {
-	// all we need here is just the week's status, not the whole week
-	weekStatus, err := GetWeekStatus(db, weekID)
+	// we need the status and the season type
+	week, err := GetWeekWithYear(db, weekID)
 	if err != nil {
 		return nil, err
 	}
-	if weekStatus != "played" {
+	if week.Status != "played" {
 		return nil, ErrWeekNotPlayed
 	}
 
 	logger.Debug("CalculatePickResults: starting for week", "week_id", weekID)
 
-	games, err := GetWeekGames(db, weekID)
+	// set up transaction
+	tx, err := db.Beginx()
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	picks, err := GetWeekPicks(db, weekID)
+	result, err := gradeWeekPicks(tx, week)
 	if err != nil {
 		return nil, err
 	}
 
-	// group picks by game ID
-	picksByGameID := make(map[string][]models.Pick)
-	for _, pick := range picks {
-		picksByGameID[pick.GameID] = append(picksByGameID[pick.GameID], pick)
+	// update week status to picks_results_calculated
+	// should this be a separate function as well?
+	if err := UpdateWeekStatus(tx, weekID, "picks_results_calculated"); err != nil {
+		return nil, err
 	}
 
-	// set up transaction
-	tx, err := db.Beginx()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("CalculatePickResults: completed", "games_processed", result.GamesProcessed, "picks_updated", result.PicksUpdated)
+
+	return result, nil
+}
+
+// gradeWeekPicks sets is_correct/is_push (and the totals equivalents) on every pick in the week.
+// Doesn't check or change the week status so score corrections can regrade an already graded week.
+func gradeWeekPicks(tx *sqlx.Tx, week *models.WeekWithYear) (*CalculatePickResultsResult, error) {
+	// straight-up survivor seasons ignore the spread
+	winningTeam := WinningTeamByGame
+	usesSpread := true
+	if week.SeasonType == SeasonTypeSurvivor && !week.SurvivorUseSpread {
+		winningTeam = WinningTeamStraightUp
+		usesSpread = false
+	}
+
+	games, err := GetWeekGames(tx, week.ID)
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
+
+	picks, err := GetWeekPicks(tx, week.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// group picks by game ID
+	picksByGameID := make(map[string][]models.Pick)
+	for _, pick := range picks {
+		picksByGameID[pick.GameID] = append(picksByGameID[pick.GameID], pick)
+	}
 
 	// use a single now
 	now := time.Now().UTC()
@@ -596,26 +772,56 @@ func CalculatePickResults(ctx context.Context, db *sqlx.DB, weekID string) (*Cal
 
 	// range over the games and determine the results
 	for _, game := range games {
+		voided := game.VoidedAt != nil
+
+		// a game that never got a spread can't be graded against it, so it counts like a voided game
+		if usesSpread && game.HomeSpread == nil && !voided {
+			logger.Warn("gradeWeekPicks: game has no spread, grading it as void", "week_id", week.ID, "game_id", game.ID)
+			voided = true
+		}
+
+		// a final game the provider sent without scores can't be graded, so its picks are left ungraded
+		// rather than counted as pushes
+		scored := game.HomeScore != nil && game.AwayScore != nil
+		if !scored && !voided {
+			logger.Warn("gradeWeekPicks: game has no score, leaving its picks ungraded", "week_id", week.ID, "game_id", game.ID)
+		}
+
 		// first find out the ID of the team that won
-		winningTeamID := WinningTeamByGame(game)
+		var winningTeamID *string
+		if !voided && scored {
+			winningTeamID = winningTeam(game)
+		}
 
-		for _, pick := range picksByGameID[game.ID] {
-			var isCorrect *bool
+		// and whether the game went over or under, if totals are on
+		var totalResult *string
+		if week.TotalsEnabled && game.Total != nil && !voided && scored {
+			totalResult = TotalResultByGame(game)
+		}
 
-			if pick.SelectedTeamID != nil && winningTeamID != nil {
-				// pointers are fun
-				value := *pick.SelectedTeamID == *winningTeamID
-				isCorrect = &value
+		for _, pick := range picksByGameID[game.ID] {
+			// picks on voided games are neither correct nor a push, so they're worth nothing to anyone
+			var isCorrect, totalIsCorrect *bool
+			isPush, totalIsPush := false, false
+			if !voided && scored {
+				isCorrect, isPush = gradePick(pick.SelectedTeamID, winningTeamID, week.PushPolicy)
+
+				if week.TotalsEnabled && game.Total != nil {
+					totalIsCorrect, totalIsPush = gradePick(pick.TotalPick, totalResult, week.PushPolicy)
+				}
 			}
 
-			// picks with no selected_team_id as well as draw games will get NULL for is_correct, but will still get a calculated_at timestamp
+			// picks with no selected_team_id will get NULL for is_correct, but will still get a calculated_at timestamp
 			query := `
 				UPDATE public.picks
 				SET is_correct = $1,
-					calculated_at = $2
-				WHERE id = $3
+					is_push = $2,
+					total_is_correct = $3,
+					total_is_push = $4,
+					calculated_at = $5
+				WHERE id = $6
 			`
-			_, err = tx.Exec(query, isCorrect, now, pick.ID)
+			_, err = tx.Exec(query, isCorrect, isPush, totalIsCorrect, totalIsPush, now, pick.ID)
 			if err != nil {
 				return nil, err
 			}
@@ -626,18 +832,6 @@ func CalculatePickResults(ctx context.Context, db *sqlx.DB, weekID string) (*Cal
 		gamesProcessed++
 	}
 
-	// update week status to picks_results_calculated
-	// should this be a separate function as well?
-	if err := UpdateWeekStatus(tx, weekID, "picks_results_calculated"); err != nil {
-		return nil, err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
-	logger.Debug("CalculatePickResults: completed", "games_processed", gamesProcessed, "picks_updated", picksUpdated)
-
 	return &CalculatePickResultsResult{
 		GamesProcessed: gamesProcessed,
 		PicksUpdated:   picksUpdated,
@@ -667,8 +861,8 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 
 	logger.Debug("CalculateWeekPoints: starting for week", "week_id", weekID)
 
-	// get settings
-	s, err := settings.Get(db)
+	// the season's settings, so a global change mid-year doesn't rescore it
+	s, err := settings.GetForWeek(db, weekID)
 	if err != nil {
 		return nil, err
 	}
@@ -679,6 +873,30 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 	}
 	defer tx.Rollback()
 
+	result, err := scoreWeek(tx, week, s.PointsPerCorrectPick)
+	if err != nil {
+		return nil, err
+	}
+
+	// update week status to scored
+	if err := UpdateWeekStatus(tx, weekID, "scored"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("CalculateWeekPoints: completed", "users_processed", result.UsersProcessed)
+
+	return result, nil
+}
+
+// scoreWeek writes week_results (points, tiebreaker diffs and ranks) for every participant.
+// Doesn't check or change the week status so score corrections can rescore an already scored week.
+func scoreWeek(tx *sqlx.Tx, week *models.WeekWithYear, pointsPerCorrectPick int) (*CalculateWeekPointsResult, error) {
+	weekID := week.ID
+
 	// calculation time
 	now := time.Now().UTC()
 
@@ -686,21 +904,51 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 	// Only participants are included - non-participants don't get week_results entries.
 	// Users with no picks for the week get 0 points (LEFT JOIN on picks).
 	type UserPoints struct {
-		UserID string `db:"id"`
-		Points int    `db:"points"`
+		UserID string  `db:"id"`
+		Points float64 `db:"points"`
 	}
 
+	// Standard seasons give points_per_correct_pick for each correct pick.
+	// Confidence seasons give each correct pick its confidence value instead.
+	// Pushes left ungraded (half and void policies) get the push multiplier of those points.
+	// Over/under picks are always worth points_per_correct_pick, with the same push handling.
+	// Survivor seasons give 1 point for every week survived, so season_standings totals are weeks survived.
 	var userPoints []UserPoints
-	err = tx.Select(&userPoints, `
-		SELECT
-			p.id,
-			COALESCE(COUNT(*) FILTER (WHERE pk.is_correct = true) * $1, 0) as points
-		FROM public.season_participants sp
-		JOIN public.profiles p ON p.id = sp.user_id
-		LEFT JOIN public.picks pk ON pk.user_id = p.id AND pk.week_id = $2
-		WHERE sp.season_id = $3
-		GROUP BY p.id
-	`, s.PointsPerCorrectPick, weekID, week.SeasonID)
+	var err error
+	if week.SeasonType == SeasonTypeSurvivor {
+		err = tx.Select(&userPoints, `
+			SELECT
+				p.id,
+				CASE WHEN ew.number IS NULL OR ew.number > $1 THEN 1 ELSE 0 END as points
+			FROM public.season_participants sp
+			JOIN public.profiles p ON p.id = sp.user_id
+			LEFT JOIN public.weeks ew ON ew.id = sp.eliminated_week_id
+			WHERE sp.season_id = $2
+		`, week.Number, week.SeasonID)
+	} else {
+		err = tx.Select(&userPoints, `
+			SELECT
+				p.id,
+				COALESCE(
+					SUM(
+						(CASE WHEN $4 = 'confidence' THEN pk.confidence ELSE $1 END)
+						* (CASE WHEN pk.is_correct = true THEN 1.0 ELSE $5::numeric END)
+					) FILTER (WHERE pk.is_correct = true OR (pk.is_push AND pk.is_correct IS NULL)),
+					0
+				)
+				+ COALESCE(
+					SUM(
+						$1 * (CASE WHEN pk.total_is_correct = true THEN 1.0 ELSE $5::numeric END)
+					) FILTER (WHERE pk.total_is_correct = true OR (pk.total_is_push AND pk.total_is_correct IS NULL)),
+					0
+				) as points
+			FROM public.season_participants sp
+			JOIN public.profiles p ON p.id = sp.user_id
+			LEFT JOIN public.picks pk ON pk.user_id = p.id AND pk.week_id = $2
+			WHERE sp.season_id = $3
+			GROUP BY p.id
+		`, pointsPerCorrectPick, weekID, week.SeasonID, week.ScoringMode, PushPointsMultiplier(week.PushPolicy))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -722,6 +970,7 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 			ON CONFLICT (user_id, week_id)
 			DO UPDATE SET
 				points = EXCLUDED.points,
+				tiebreaker_diff = NULL,
 				computed_at = EXCLUDED.computed_at,
 				updated_at = EXCLUDED.updated_at
 		`, resultID, up.UserID, weekID, up.Points, now, now, now)
@@ -731,14 +980,32 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 		usersProcessed++
 	}
 
-	// calculate and update ranks
+	// how far off each user's tiebreaker prediction was from the combined score of the tiebreaker game
+	_, err = tx.Exec(`
+		UPDATE public.week_results wr
+		SET tiebreaker_diff = ABS(wt.predicted_total - (g.home_score + g.away_score))
+		FROM public.week_tiebreakers wt
+		JOIN public.games g ON g.id = wt.game_id
+		WHERE wr.week_id = $1
+		AND wt.week_id = wr.week_id
+		AND wt.user_id = wr.user_id
+		AND g.home_score IS NOT NULL
+		AND g.away_score IS NOT NULL
+		AND g.voided_at IS NULL
+	`, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	// calculate and update ranks.  Ties on points go to the closest tiebreaker prediction,
+	// users without a prediction lose the tiebreak
 	_, err = tx.Exec(`
 		UPDATE public.week_results
 		SET rank = subquery.rank
 		FROM (
 			SELECT
 				id,
-				RANK() OVER (ORDER BY points DESC) as rank
+				RANK() OVER (ORDER BY points DESC, tiebreaker_diff ASC NULLS LAST) as rank
 			FROM public.week_results
 			WHERE week_id = $1
 		) subquery
@@ -748,17 +1015,6 @@ func CalculateWeekPoints(ctx context.Context, db *sqlx.DB, weekID string) (*Calc
 		return nil, err
 	}
 
-	// update week status to scored
-	if err := UpdateWeekStatus(tx, weekID, "scored"); err != nil {
-		return nil, err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
-	logger.Debug("CalculateWeekPoints: completed", "users_processed", usersProcessed)
-
 	return &CalculateWeekPointsResult{
 		UsersProcessed: usersProcessed,
 	}, nil
@@ -793,13 +1049,35 @@ func CalculateSeasonSnapshot(ctx context.Context, db *sqlx.DB, weekID string) (*
 	}
 	defer tx.Rollback()
 
+	result, err := snapshotSeasonWeek(tx, week.SeasonID, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	// update week status to final and set closed_at
+	if _, err := tx.Exec(`UPDATE public.weeks SET status = 'final', closed_at = NOW(), updated_at = NOW() WHERE id = $1`, weekID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("CalculateSeasonSnapshot: completed", "users_processed", result.UsersProcessed, "status", "final")
+
+	return result, nil
+}
+
+// snapshotSeasonWeek writes season_standings for a week by adding its week_results to the previous week's standings.
+// Doesn't check or change the week status so score corrections can rebuild already final weeks in order.
+func snapshotSeasonWeek(tx *sqlx.Tx, seasonID string, weekID string) (*CalculateSeasonSnapshotResult, error) {
 	// load this week's results
 	type WeekResult struct {
-		UserID string `db:"user_id"`
-		Points int    `db:"points"`
+		UserID string  `db:"user_id"`
+		Points float64 `db:"points"`
 	}
 	var weekResults []WeekResult
-	err = tx.Select(&weekResults, `SELECT user_id, points FROM public.week_results WHERE week_id = $1`, weekID)
+	err := tx.Select(&weekResults, `SELECT user_id, points FROM public.week_results WHERE week_id = $1`, weekID)
 	if err != nil {
 		return nil, err
 	}
@@ -822,13 +1100,13 @@ func CalculateSeasonSnapshot(ctx context.Context, db *sqlx.DB, weekID string) (*
 			ORDER BY number DESC
 			LIMIT 1
 		)
-	`, week.SeasonID, weekID)
+	`, seasonID, weekID)
 	if err != nil {
 		return nil, err
 	}
 
 	// create map of previous points
-	previousPointsByUserID := make(map[string]int)
+	previousPointsByUserID := make(map[string]float64)
 	for _, standing := range previousStandings {
 		previousPointsByUserID[standing.UserID] = standing.Points
 	}
@@ -856,7 +1134,7 @@ func CalculateSeasonSnapshot(ctx context.Context, db *sqlx.DB, weekID string) (*
 				points = EXCLUDED.points,
 				computed_at = EXCLUDED.computed_at,
 				updated_at = EXCLUDED.updated_at
-		`, standingID, weekResult.UserID, week.SeasonID, weekID, cumulativePoints, now, now, now)
+		`, standingID, weekResult.UserID, seasonID, weekID, cumulativePoints, now, now, now)
 		if err != nil {
 			return nil, err
 		}
@@ -875,22 +1153,11 @@ func CalculateSeasonSnapshot(ctx context.Context, db *sqlx.DB, weekID string) (*
 			WHERE season_id = $1 AND week_id = $2
 		) subquery
 		WHERE season_standings.id = subquery.id
-	`, week.SeasonID, weekID)
+	`, seasonID, weekID)
 	if err != nil {
 		return nil, err
 	}
 
-	// update week status to final and set closed_at
-	if _, err := tx.Exec(`UPDATE public.weeks SET status = 'final', closed_at = NOW(), updated_at = NOW() WHERE id = $1`, weekID); err != nil {
-		return nil, err
-	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
-	logger.Debug("CalculateSeasonSnapshot: completed", "users_processed", usersProcessed, "status", "final")
-
 	return &CalculateSeasonSnapshotResult{
 		UsersProcessed: usersProcessed,
 	}, nil
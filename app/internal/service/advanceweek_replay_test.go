@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// Recorded games fixtures for week 1 of 2024 (see external/fixtures.go), before kickoff and after the final
+// whistle.  Replay re-reads the directory on every fetch, so switching PROVIDER_FIXTURES_DIR plays the week out
+const (
+	scheduledFixturesDir = "testdata/fixtures/scheduled"
+	finalFixturesDir     = "testdata/fixtures/final"
+)
+
+// the providers and services log as they go
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// TestAdvanceWeekStateReplaysFixtures runs a week from draft to final against the recorded fixtures.
+// It needs a database with the supabase migrations applied (supabase start): set TEST_DATABASE_URL to run it.
+// Everything it creates is in its own league and is deleted afterwards
+func TestAdvanceWeekStateReplaysFixtures(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sqlx.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	userID, weekID := seedReplayWeek(t, db)
+
+	t.Setenv("PROVIDER_FIXTURES", "replay")
+	t.Setenv("PROVIDER_FIXTURES_DIR", scheduledFixturesDir)
+
+	// draft: the games are imported from the fixture, then it waits for spreads
+	week := loadReplayWeek(t, db, weekID)
+	if err := AdvanceWeekState(ctx, db, week, userID); !errors.Is(err, ErrManualActionRequired) {
+		t.Fatalf("advance from draft: got %v, want ErrManualActionRequired", err)
+	}
+	if week.Status != StatusGamesImported {
+		t.Fatalf("status after import: got %s, want %s", week.Status, StatusGamesImported)
+	}
+
+	var gameCount int
+	mustGet(t, db, &gameCount, `SELECT COUNT(*) FROM public.games WHERE week_id = $1`, weekID)
+	if gameCount != 2 {
+		t.Fatalf("imported games: got %d, want 2", gameCount)
+	}
+
+	// replay resolves team names with the recording provider's aliases, like the live import
+	var awayAbbr string
+	mustGet(t, db, &awayAbbr, `SELECT away_team_abbr FROM public.games WHERE week_id = $1 AND external_game_id = 7002`, weekID)
+	if awayAbbr != "GB" {
+		t.Errorf("away team of game 7002: got %s, want GB (from the espn alias GNB)", awayAbbr)
+	}
+
+	// the commissioner's part: spreads, activation and a locked pick on each game.
+	// KC -2.5 covers (27-20), GB +1.5 doesn't (29-34)
+	mustExec(t, db, `UPDATE public.games SET home_spread = -2.5 WHERE week_id = $1 AND external_game_id = 7001`, weekID)
+	mustExec(t, db, `UPDATE public.games SET home_spread = -1.5 WHERE week_id = $1 AND external_game_id = 7002`, weekID)
+	mustExec(t, db, `UPDATE public.weeks SET status = 'active', activated_at = NOW() WHERE id = $1`, weekID)
+	insertReplayPick(t, db, userID, weekID, 7001, "home")
+	insertReplayPick(t, db, userID, weekID, 7002, "away")
+
+	// active, games not played yet
+	week = loadReplayWeek(t, db, weekID)
+	if err := AdvanceWeekState(ctx, db, week, userID); !errors.Is(err, ErrWaitingForGames) {
+		t.Fatalf("advance before the games are played: got %v, want ErrWaitingForGames", err)
+	}
+
+	// the final scores come in and the week runs the rest of the way
+	t.Setenv("PROVIDER_FIXTURES_DIR", finalFixturesDir)
+	week = loadReplayWeek(t, db, weekID)
+	if err := AdvanceWeekState(ctx, db, week, userID); err != nil {
+		t.Fatalf("advance after the games are final: %v", err)
+	}
+	if week.Status != StatusFinal {
+		t.Fatalf("status after scores: got %s, want %s", week.Status, StatusFinal)
+	}
+
+	var picks []struct {
+		ExternalGameID int64 `db:"external_game_id"`
+		IsCorrect      *bool `db:"is_correct"`
+	}
+	err = db.Select(&picks, `
+		SELECT g.external_game_id, p.is_correct
+		FROM public.picks p
+		JOIN public.games g ON g.id = p.game_id
+		WHERE p.week_id = $1
+		ORDER BY g.external_game_id
+	`, weekID)
+	if err != nil {
+		t.Fatalf("load picks: %v", err)
+	}
+	wantCorrect := map[int64]bool{7001: true, 7002: false}
+	if len(picks) != len(wantCorrect) {
+		t.Fatalf("graded picks: got %d, want %d", len(picks), len(wantCorrect))
+	}
+	for _, pick := range picks {
+		if pick.IsCorrect == nil || *pick.IsCorrect != wantCorrect[pick.ExternalGameID] {
+			t.Errorf("pick on game %d: got is_correct %v, want %v", pick.ExternalGameID, pick.IsCorrect, wantCorrect[pick.ExternalGameID])
+		}
+	}
+
+	// points is numeric (a push can be worth half a pick)
+	var points float64
+	mustGet(t, db, &points, `SELECT points FROM public.week_results WHERE week_id = $1 AND user_id = $2`, weekID, userID)
+	if points != 1 {
+		t.Errorf("week points: got %v, want 1", points)
+	}
+
+	var standings int
+	mustGet(t, db, &standings, `SELECT COUNT(*) FROM public.season_standings WHERE week_id = $1`, weekID)
+	if standings != 1 {
+		t.Errorf("season standings rows: got %d, want 1", standings)
+	}
+}
+
+// seedReplayWeek creates a user, a league with a 2024 season and a draft week 1, and the teams and alias the fixtures use.
+// Returns the user and week ids
+func seedReplayWeek(t *testing.T, db *sqlx.DB) (string, string) {
+	t.Helper()
+
+	var userID string
+	mustGet(t, db, &userID, `INSERT INTO auth.users (id, email) VALUES (gen_random_uuid(), $1) RETURNING id`, newTestID(t)+"@replay.test")
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM auth.users WHERE id = $1`, userID); err != nil {
+			t.Errorf("delete test user: %v", err)
+		}
+	})
+
+	// games hold on to their teams, so leave them (and any the database already has) in place
+	teams := []struct{ Abbr, Name, City string }{
+		{"KC", "Chiefs", "Kansas City"},
+		{"BAL", "Ravens", "Baltimore"},
+		{"PHI", "Eagles", "Philadelphia"},
+		{"GB", "Packers", "Green Bay"},
+	}
+	for _, team := range teams {
+		mustExec(t, db, `
+			INSERT INTO public.teams (id, name, abbreviation, city)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (abbreviation) DO NOTHING
+		`, newTestID(t), team.Name, team.Abbr, team.City)
+	}
+
+	// the fixtures were recorded from ESPN, which (in the fixture) calls Green Bay GNB
+	aliasID := newTestID(t)
+	mustExec(t, db, `
+		INSERT INTO public.team_aliases (id, provider, alias, team_id)
+		SELECT $1, 'espn', 'GNB', id FROM public.teams WHERE abbreviation = 'GB'
+		ON CONFLICT (provider, lower(alias)) DO NOTHING
+	`, aliasID)
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM public.team_aliases WHERE id = $1`, aliasID); err != nil {
+			t.Errorf("delete test alias: %v", err)
+		}
+	})
+
+	leagueID, seasonID, weekID := newTestID(t), newTestID(t), newTestID(t)
+	mustExec(t, db, `INSERT INTO public.leagues (id, name, created_by) VALUES ($1, 'Fixture replay', $2)`, leagueID, userID)
+	t.Cleanup(func() {
+		// seasons, weeks, games, picks and results all cascade from the league
+		if _, err := db.Exec(`DELETE FROM public.leagues WHERE id = $1`, leagueID); err != nil {
+			t.Errorf("delete test league: %v", err)
+		}
+	})
+
+	mustExec(t, db, `INSERT INTO public.seasons (id, year, created_by, league_id) VALUES ($1, 2024, $2, $3)`, seasonID, userID, leagueID)
+	mustExec(t, db, `
+		INSERT INTO public.season_settings (
+			season_id, pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick,
+			allow_commissioner_overrides, allow_picks_after_kickoff, pick_lock_rule
+		)
+		VALUES ($1, 0, true, 1, true, false, 'per_game')
+	`, seasonID)
+	mustExec(t, db, `INSERT INTO public.season_participants (season_id, user_id) VALUES ($1, $2)`, seasonID, userID)
+	mustExec(t, db, `INSERT INTO public.weeks (id, season_id, number, created_by) VALUES ($1, $2, 1, $3)`, weekID, seasonID, userID)
+
+	return userID, weekID
+}
+
+// insertReplayPick locks in a pick on the home or away team of a fixture game
+func insertReplayPick(t *testing.T, db *sqlx.DB, userID string, weekID string, externalGameID int64, side string) {
+	t.Helper()
+	mustExec(t, db, `
+		INSERT INTO public.picks (id, user_id, game_id, week_id, selected_team_id, user_locked_at)
+		SELECT $1, $2, g.id, g.week_id, CASE WHEN $5 = 'home' THEN g.home_team_id ELSE g.away_team_id END, NOW()
+		FROM public.games g
+		WHERE g.week_id = $3 AND g.external_game_id = $4
+	`, newTestID(t), userID, weekID, externalGameID, side)
+}
+
+func loadReplayWeek(t *testing.T, db *sqlx.DB, weekID string) *models.Week {
+	t.Helper()
+	var week models.Week
+	mustGet(t, db, &week, `SELECT * FROM public.weeks WHERE id = $1`, weekID)
+	return &week
+}
+
+func newTestID(t *testing.T) string {
+	t.Helper()
+	newID, err := id.New()
+	if err != nil {
+		t.Fatalf("generate id: %v", err)
+	}
+	return newID
+}
+
+func mustExec(t *testing.T, db *sqlx.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("exec: %v\n%s", err, query)
+	}
+}
+
+func mustGet(t *testing.T, db *sqlx.DB, dest any, query string, args ...any) {
+	t.Helper()
+	if err := db.Get(dest, query, args...); err != nil {
+		t.Fatalf("query: %v\n%s", err, query)
+	}
+}
@@ -11,21 +11,27 @@ import (
 
 // GetBadges returns a map of user ID to badges for the given season.
 // Badges include: previous week winner (most recent final week in this season),
-// and previous season winner/loser (final standings of the prior year's season).
+// and previous season winner/loser (final standings of the league's season the prior year).
 func GetBadges(db *sqlx.DB, seasonID string) (map[string][]models.Badge, error) {
 	badges := make(map[string][]models.Badge)
 
-	// get the season's year so we can look up the previous season
-	var seasonYear int
-	err := db.Get(&seasonYear, `SELECT year FROM seasons WHERE id = $1`, seasonID)
+	// get the season's year, type and league so we can look up the previous season
+	var season struct {
+		Year       int    `db:"year"`
+		SeasonType string `db:"season_type"`
+		LeagueID   string `db:"league_id"`
+	}
+	err := db.Get(&season, `SELECT year, season_type, league_id FROM seasons WHERE id = $1`, seasonID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return badges, nil
 		}
 		return nil, err
 	}
+	seasonYear := season.Year
 
-	// previous week winner or winners: rank 1 in week_results for the most recent final week
+	// previous week winner or winners: rank 1 in week_results for the most recent final week.
+	// rank already has the tiebreaker applied so a tiebreak winner gets the badge alone
 	type weekWinner struct {
 		UserID     string `db:"user_id"`
 		WeekNumber int    `db:"week_number"`
@@ -54,9 +60,12 @@ func GetBadges(db *sqlx.DB, seasonID string) (map[string][]models.Badge, error)
 		})
 	}
 
-	// previous season winner and loser: final standings from the prior year
+	// previous season winner and loser: final standings from the league's season the prior year
 	var prevSeasonID string
-	err = db.Get(&prevSeasonID, `SELECT id FROM seasons WHERE year = $1 AND is_postseason = false`, seasonYear-1)
+	err = db.Get(&prevSeasonID, `
+		SELECT id FROM seasons
+		WHERE year = $1 AND is_postseason = false AND season_type = $2 AND league_id = $3
+	`, seasonYear-1, season.SeasonType, season.LeagueID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return badges, nil
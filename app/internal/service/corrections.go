@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+var (
+	ErrGameNotFound       = errors.New("game not found")
+	ErrWeekNotCorrectable = errors.New("week has not been played yet")
+	ErrGameVoided         = errors.New("game is voided")
+)
+
+type CorrectGameResultResult struct {
+	GameID            string
+	WeekID            string
+	PreviousHomeScore *int
+	PreviousAwayScore *int
+	HomeScore         int
+	AwayScore         int
+	PicksUpdated      int
+	WeeksRescored     int
+	WeeksResnapshot   int
+}
+
+// CorrectGameResult sets a game's final score after the week has been played and recomputes everything
+// that depends on it (see recalculateFromWeek) in the same transaction.  Voided games can't be corrected
+func CorrectGameResult(ctx context.Context, db *sqlx.DB, gameID string, homeScore int, awayScore int) (*CorrectGameResultResult, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// lock the week, then the game, so corrections, voids and advancing or reverting the week can't interleave
+	weekID, err := lockGameWeek(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var game struct {
+		HomeScore *int       `db:"home_score"`
+		AwayScore *int       `db:"away_score"`
+		VoidedAt  *time.Time `db:"voided_at"`
+	}
+	err = tx.Get(&game, `
+		SELECT home_score, away_score, voided_at
+		FROM public.games
+		WHERE id = $1
+		FOR UPDATE
+	`, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+	if game.VoidedAt != nil {
+		return nil, ErrGameVoided
+	}
+
+	week, err := GetWeekWithYear(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	// active weeks get their scores from the normal import
+	if !isWeekPast(week.Status, StatusPlayed) {
+		return nil, ErrWeekNotCorrectable
+	}
+
+	s, err := settings.GetForSeason(tx, week.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(
+		"correcting game result",
+		"game_id", gameID,
+		"week_id", week.ID,
+		"week_status", week.Status,
+		"home_score", homeScore,
+		"away_score", awayScore,
+	)
+
+	_, err = tx.Exec(`
+		UPDATE public.games
+		SET home_score = $1,
+			away_score = $2,
+			status = 'final',
+			updated_at = NOW()
+		WHERE id = $3
+	`, homeScore, awayScore, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CorrectGameResultResult{
+		GameID:            gameID,
+		WeekID:            week.ID,
+		PreviousHomeScore: game.HomeScore,
+		PreviousAwayScore: game.AwayScore,
+		HomeScore:         homeScore,
+		AwayScore:         awayScore,
+	}
+
+	recalc, err := recalculateFromWeek(tx, week, s.PointsPerCorrectPick)
+	if err != nil {
+		return nil, err
+	}
+	result.PicksUpdated = recalc.PicksUpdated
+	result.WeeksRescored = recalc.WeeksRescored
+	result.WeeksResnapshot = recalc.WeeksResnapshot
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Info(
+		"game result corrected",
+		"game_id", gameID,
+		"picks_updated", result.PicksUpdated,
+		"weeks_rescored", result.WeeksRescored,
+		"weeks_resnapshot", result.WeeksResnapshot,
+	)
+
+	return result, nil
+}
+
+// lockGameWeek locks the week a game is in and returns its id.  The week is locked before the game (the order
+// RefreshWeekSchedule and CreateGame use) so game changes can't deadlock with them
+func lockGameWeek(tx *sqlx.Tx, gameID string) (string, error) {
+	var weekID string
+	if err := tx.Get(&weekID, `SELECT week_id FROM public.games WHERE id = $1`, gameID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrGameNotFound
+		}
+		return "", err
+	}
+	if _, err := tx.Exec(`SELECT 1 FROM public.weeks WHERE id = $1 FOR UPDATE`, weekID); err != nil {
+		return "", err
+	}
+	return weekID, nil
+}
+
+type weekRecalculation struct {
+	PicksUpdated    int
+	WeeksRescored   int
+	WeeksResnapshot int
+}
+
+// recalculateFromWeek recomputes everything downstream of a week's games after one of them changed:
+// the week's pick results, survivor eliminations, the week_results for that week and every later week,
+// and every season_standings snapshot from that week on.
+// Each step only runs for weeks that had already reached it, so week statuses never change.
+func recalculateFromWeek(tx *sqlx.Tx, week *models.WeekWithYear, pointsPerCorrectPick int) (*weekRecalculation, error) {
+	recalc := &weekRecalculation{}
+
+	// regrade the picks for this game's week.  Only this week has picks on the game
+	if isWeekPast(week.Status, StatusPicksResultsCalculated) {
+		graded, err := gradeWeekPicks(tx, week)
+		if err != nil {
+			return nil, err
+		}
+		recalc.PicksUpdated = graded.PicksUpdated
+	}
+
+	// this week and every later week, oldest first so standings build forward correctly
+	var weekIDs []string
+	err := tx.Select(&weekIDs, `
+		SELECT id
+		FROM public.weeks
+		WHERE season_id = $1
+		AND number >= $2
+		ORDER BY number
+	`, week.SeasonID, week.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	laterWeeks := make([]*models.WeekWithYear, 0, len(weekIDs))
+	for _, weekID := range weekIDs {
+		w, err := GetWeekWithYear(tx, weekID)
+		if err != nil {
+			return nil, err
+		}
+		laterWeeks = append(laterWeeks, w)
+	}
+
+	// survivor eliminations from this week on may have changed, so undo them and replay every week
+	// that had already applied them.  Eliminations are applied on the way to scored
+	if week.SeasonType == SeasonTypeSurvivor {
+		_, err := tx.Exec(`
+			UPDATE public.season_participants sp
+			SET eliminated_week_id = NULL,
+				eliminated_at = NULL
+			FROM public.weeks ew
+			WHERE ew.id = sp.eliminated_week_id
+			AND sp.season_id = $1
+			AND ew.number >= $2
+		`, week.SeasonID, week.Number)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, lw := range laterWeeks {
+			if !isWeekPast(lw.Status, StatusScored) {
+				continue
+			}
+			if _, err := applySurvivorEliminations(tx, lw); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// rescore every week that already has week_results.  Pick'em points only change for the corrected week,
+	// but survivor points depend on eliminations so later weeks are rescored too
+	for _, lw := range laterWeeks {
+		if !isWeekPast(lw.Status, StatusScored) {
+			continue
+		}
+		if _, err := scoreWeek(tx, lw, pointsPerCorrectPick); err != nil {
+			return nil, err
+		}
+		recalc.WeeksRescored++
+	}
+
+	// standings are cumulative so every final week from here on gets rebuilt
+	for _, lw := range laterWeeks {
+		if lw.Status != StatusFinal {
+			continue
+		}
+		if _, err := snapshotSeasonWeek(tx, week.SeasonID, lw.ID); err != nil {
+			return nil, err
+		}
+		recalc.WeeksResnapshot++
+	}
+
+	return recalc, nil
+}
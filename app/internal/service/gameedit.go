@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// game_audit_log actions
+const (
+	GameAuditCreate = "create"
+	GameAuditUpdate = "update"
+	GameAuditDelete = "delete"
+)
+
+// unique indexes on games (season_id, external_game_id).  The survivor migration added the first and the leagues
+// migration an equivalent second one, so a duplicate can be reported against either
+var externalGameIDUniqueIndexes = map[string]bool{
+	"games_external_game_id_season_uniq": true,
+	"games_season_external_game_id_uniq": true,
+}
+
+var (
+	ErrWeekGamesLocked     = errors.New("games can only be changed before the week is activated")
+	ErrTeamAlreadyPlaying  = errors.New("team already has a game this week")
+	ErrTeamPlaysItself     = errors.New("a team can't play itself")
+	ErrExternalGameIDTaken = errors.New("another game in this season already has this external game id")
+	ErrInvalidGameValue    = errors.New("invalid game value")
+	ErrNoGameChanges       = errors.New("nothing to change")
+)
+
+// canEditWeekGames is true until the week is activated
+func canEditWeekGames(status string) bool {
+	return status == StatusDraft || status == StatusGamesImported || status == StatusSpreadsSet
+}
+
+// GameInput is a single game added by a commissioner.  Teams can be abbreviations, full names or aliases
+type GameInput struct {
+	HomeTeam       string    `json:"home_team" binding:"required"`
+	AwayTeam       string    `json:"away_team" binding:"required"`
+	KickoffTime    time.Time `json:"kickoff_time" binding:"required"`
+	NeutralSite    bool      `json:"neutral_site"`
+	ExternalGameID *int64    `json:"external_game_id"`
+	HomeSpread     *float64  `json:"home_spread"`
+	Total          *float64  `json:"total"`
+}
+
+// GameUpdate is a partial edit of a game.  Only the fields that are set change.  Spreads are still set with
+// the spreads endpoints
+type GameUpdate struct {
+	HomeTeam       *string    `json:"home_team"`
+	AwayTeam       *string    `json:"away_team"`
+	KickoffTime    *time.Time `json:"kickoff_time"`
+	NeutralSite    *bool      `json:"neutral_site"`
+	ExternalGameID *int64     `json:"external_game_id"`
+
+	// unlinks the game from the provider (external_game_id can't be sent as null to clear it)
+	ClearExternalGameID bool `json:"clear_external_game_id"`
+}
+
+// GameAuditEntry is one recorded game change
+type GameAuditEntry struct {
+	ID                string          `json:"id" db:"id"`
+	GameID            string          `json:"game_id" db:"game_id"`
+	WeekID            string          `json:"week_id" db:"week_id"`
+	Action            string          `json:"action" db:"action"`
+	Before            json.RawMessage `json:"before" db:"before"`
+	After             json.RawMessage `json:"after" db:"after"`
+	ChangedBy         string          `json:"changed_by" db:"changed_by"`
+	ChangedByUsername *string         `json:"changed_by_username" db:"changed_by_username"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CreateGame adds one game to a week that hasn't been activated.  A draft week moves to games_imported and
+// a spread moves the week to spreads_set, like the manual import
+func CreateGame(ctx context.Context, db *sqlx.DB, weekID string, input GameInput, actingUserID string) (*models.Game, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	week, err := lockWeekForGameEdit(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	homeTeamID, homeAbbr, err := manualImportTeam(tx, input.HomeTeam)
+	if err != nil {
+		return nil, err
+	}
+	awayTeamID, awayAbbr, err := manualImportTeam(tx, input.AwayTeam)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGameTeams(tx, weekID, "", homeTeamID, awayTeamID); err != nil {
+		return nil, err
+	}
+
+	if input.HomeSpread != nil && !isHalfPoint(*input.HomeSpread) {
+		return nil, fmt.Errorf("%w: spreads must be multiples of 0.5", ErrInvalidGameValue)
+	}
+	if input.Total != nil && (*input.Total < 0 || !isHalfPoint(*input.Total)) {
+		return nil, fmt.Errorf("%w: totals must be positive multiples of 0.5", ErrInvalidGameValue)
+	}
+
+	gameID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	var game models.Game
+	err = tx.Get(&game, `
+		INSERT INTO public.games (
+			id,
+			week_id,
+			season_id,
+			external_game_id,
+			kickoff_time,
+			home_team_id,
+			away_team_id,
+			home_team_abbr,
+			away_team_abbr,
+			created_by,
+			home_spread,
+			total,
+			neutral_site,
+			status
+		)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+		RETURNING *
+	`,
+		gameID,
+		weekID,
+		week.SeasonID,
+		input.ExternalGameID,
+		input.KickoffTime,
+		homeTeamID,
+		awayTeamID,
+		homeAbbr,
+		awayAbbr,
+		actingUserID,
+		input.HomeSpread,
+		input.Total,
+		input.NeutralSite,
+		GameStatusScheduled,
+	)
+	if err != nil {
+		return nil, gameWriteError(err)
+	}
+
+	if err := writeGameAudit(tx, GameAuditCreate, &game, nil, &game, actingUserID); err != nil {
+		return nil, err
+	}
+
+	newStatus := week.Status
+	if newStatus == StatusDraft {
+		newStatus = StatusGamesImported
+	}
+	if input.HomeSpread != nil {
+		newStatus = StatusSpreadsSet
+	}
+	if newStatus != week.Status {
+		if err := UpdateWeekStatus(tx, weekID, newStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("game created", "game_id", game.ID, "week_id", weekID, "matchup", awayAbbr+" @ "+homeAbbr, "created_by", actingUserID)
+
+	return &game, nil
+}
+
+// UpdateGame changes a game's teams, kickoff time, neutral site flag or external id before its week is activated.
+// Swapping home and away flips the spread; any other team change clears the spread and total since they were
+// set for the old matchup
+func UpdateGame(ctx context.Context, db *sqlx.DB, gameID string, update GameUpdate, actingUserID string) (*models.Game, error) {
+	if update.HomeTeam == nil && update.AwayTeam == nil && update.KickoffTime == nil &&
+		update.NeutralSite == nil && update.ExternalGameID == nil && !update.ClearExternalGameID {
+		return nil, ErrNoGameChanges
+	}
+	if update.ClearExternalGameID && update.ExternalGameID != nil {
+		return nil, fmt.Errorf("%w: send external_game_id or clear_external_game_id, not both", ErrInvalidGameValue)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockGameForEdit(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	homeTeamID, homeAbbr := before.HomeTeamID, before.HomeTeamAbbr
+	if update.HomeTeam != nil {
+		if homeTeamID, homeAbbr, err = manualImportTeam(tx, *update.HomeTeam); err != nil {
+			return nil, err
+		}
+	}
+	awayTeamID, awayAbbr := before.AwayTeamID, before.AwayTeamAbbr
+	if update.AwayTeam != nil {
+		if awayTeamID, awayAbbr, err = manualImportTeam(tx, *update.AwayTeam); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkGameTeams(tx, before.WeekID, gameID, homeTeamID, awayTeamID); err != nil {
+		return nil, err
+	}
+
+	kickoff := before.KickoffTime
+	if update.KickoffTime != nil {
+		kickoff = *update.KickoffTime
+	}
+	neutralSite := before.NeutralSite
+	if update.NeutralSite != nil {
+		neutralSite = *update.NeutralSite
+	}
+
+	swapped := homeTeamID == before.AwayTeamID && awayTeamID == before.HomeTeamID
+	teamsChanged := !swapped && (homeTeamID != before.HomeTeamID || awayTeamID != before.AwayTeamID)
+
+	var after models.Game
+	err = tx.Get(&after, `
+		UPDATE public.games
+		SET home_team_id = $2,
+			away_team_id = $3,
+			home_team_abbr = $4,
+			away_team_abbr = $5,
+			kickoff_time = $6,
+			neutral_site = $7,
+			external_game_id = CASE WHEN $9 THEN NULL ELSE COALESCE($8, external_game_id) END,
+			home_spread = CASE WHEN $10 THEN -home_spread WHEN $11 THEN NULL ELSE home_spread END,
+			total = CASE WHEN $11 THEN NULL ELSE total END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`, gameID, homeTeamID, awayTeamID, homeAbbr, awayAbbr, kickoff, neutralSite, update.ExternalGameID,
+		update.ClearExternalGameID, swapped, teamsChanged)
+	if err != nil {
+		return nil, gameWriteError(err)
+	}
+
+	if err := writeGameAudit(tx, GameAuditUpdate, &after, before, &after, actingUserID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("game updated", "game_id", gameID, "week_id", after.WeekID, "updated_by", actingUserID)
+
+	return &after, nil
+}
+
+// DeleteGame removes a game (a duplicate, or one that won't be played) before its week is activated
+func DeleteGame(ctx context.Context, db *sqlx.DB, gameID string, actingUserID string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockGameForEdit(tx, gameID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM public.games WHERE id = $1`, gameID); err != nil {
+		return err
+	}
+
+	if err := writeGameAudit(tx, GameAuditDelete, before, before, nil, actingUserID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Warn("game deleted", "game_id", gameID, "week_id", before.WeekID, "matchup", before.AwayTeamAbbr+" @ "+before.HomeTeamAbbr, "deleted_by", actingUserID)
+
+	return nil
+}
+
+// GetGameAuditLog returns every recorded game change for a week, newest first
+func GetGameAuditLog(db *sqlx.DB, weekID string) ([]GameAuditEntry, error) {
+	entries := []GameAuditEntry{}
+	err := db.Select(&entries, `
+		SELECT
+			gal.id,
+			gal.game_id,
+			gal.week_id,
+			gal.action,
+			gal.before,
+			gal.after,
+			gal.changed_by,
+			p.username AS changed_by_username,
+			gal.created_at
+		FROM public.game_audit_log gal
+		LEFT JOIN public.profiles p ON p.id = gal.changed_by
+		WHERE gal.week_id = $1
+		ORDER BY gal.created_at DESC
+	`, weekID)
+	return entries, err
+}
+
+type gameEditWeek struct {
+	SeasonID string `db:"season_id"`
+	Status   string `db:"status"`
+}
+
+// lockWeekForGameEdit locks the week and checks its games can still be changed
+func lockWeekForGameEdit(tx *sqlx.Tx, weekID string) (*gameEditWeek, error) {
+	var week gameEditWeek
+	err := tx.Get(&week, `SELECT season_id, status FROM public.weeks WHERE id = $1 FOR UPDATE`, weekID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWeekNotFound
+		}
+		return nil, err
+	}
+	if !canEditWeekGames(week.Status) {
+		return nil, ErrWeekGamesLocked
+	}
+	return &week, nil
+}
+
+// lockGameForEdit loads a game, locking its week and then the game, and checks the week hasn't been activated.
+// The week is locked first, the same order as RefreshWeekSchedule and CreateGame, so they can't deadlock
+func lockGameForEdit(tx *sqlx.Tx, gameID string) (*models.Game, error) {
+	var weekID string
+	err := tx.Get(&weekID, `SELECT week_id FROM public.games WHERE id = $1`, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := lockWeekForGameEdit(tx, weekID); err != nil {
+		return nil, err
+	}
+
+	var game models.Game
+	err = tx.Get(&game, `SELECT * FROM public.games WHERE id = $1 FOR UPDATE`, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+	return &game, nil
+}
+
+// checkGameTeams makes sure the teams are different and neither already plays another game this week.
+// excludeGameID is the game being edited
+func checkGameTeams(q sqlx.Queryer, weekID string, excludeGameID string, homeTeamID string, awayTeamID string) error {
+	if homeTeamID == awayTeamID {
+		return ErrTeamPlaysItself
+	}
+
+	var playing bool
+	err := sqlx.Get(q, &playing, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.games
+			WHERE week_id = $1
+			  AND id <> $2
+			  AND (home_team_id IN ($3, $4) OR away_team_id IN ($3, $4))
+		)
+	`, weekID, excludeGameID, homeTeamID, awayTeamID)
+	if err != nil {
+		return err
+	}
+	if playing {
+		return ErrTeamAlreadyPlaying
+	}
+	return nil
+}
+
+// gameWriteError turns an external_game_id unique index violation into ErrExternalGameIDTaken
+func gameWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && externalGameIDUniqueIndexes[pgErr.ConstraintName] {
+		return ErrExternalGameIDTaken
+	}
+	return err
+}
+
+// writeGameAudit records a game change.  before is nil for creates and after is nil for deletes
+func writeGameAudit(e sqlx.Execer, action string, game *models.Game, before *models.Game, after *models.Game, actingUserID string) error {
+	auditID, err := id.New()
+	if err != nil {
+		return err
+	}
+
+	var beforeJSON, afterJSON []byte
+	if before != nil {
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if afterJSON, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	_, err = e.Exec(`
+		INSERT INTO public.game_audit_log (id, game_id, week_id, action, before, after, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, auditID, game.ID, game.WeekID, action, nullableJSON(beforeJSON), nullableJSON(afterJSON), actingUserID)
+	return err
+}
+
+// nullableJSON sends a nil slice as NULL instead of an empty string
+func nullableJSON(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
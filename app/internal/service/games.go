@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+// Game status values (public.game_status).  Same values as the external provider statuses
+const (
+	GameStatusScheduled  = "scheduled"
+	GameStatusInProgress = "in_progress"
+	GameStatusFinal      = "final"
+	GameStatusPostponed  = "postponed"
+	GameStatusCancelled  = "cancelled"
+)
+
+// reason stored on games the provider cancelled
+const VoidReasonCancelled = "Cancelled"
+
+var (
+	ErrGameAlreadyVoided  = errors.New("game is already voided")
+	ErrWeekNotStarted     = errors.New("week has not been activated yet")
+	ErrVoidReasonRequired = errors.New("a reason is required to void a game")
+)
+
+type VoidGameResult struct {
+	GameID          string
+	WeekID          string
+	PicksUpdated    int
+	WeeksRescored   int
+	WeeksResnapshot int
+}
+
+// VoidGame takes a game out of scoring for everyone.  If the week's picks were already graded they're
+// recalculated (see recalculateFromWeek) in the same transaction.
+// Only games in weeks that have been activated can be voided
+func VoidGame(ctx context.Context, db *sqlx.DB, gameID string, reason string, actingUserID string) (*VoidGameResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrVoidReasonRequired
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// lock the week, then the game, so voids, corrections and advancing or reverting the week can't interleave
+	weekID, err := lockGameWeek(tx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var voidedAt *time.Time
+	err = tx.Get(&voidedAt, `
+		SELECT voided_at
+		FROM public.games
+		WHERE id = $1
+		FOR UPDATE
+	`, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+	if voidedAt != nil {
+		return nil, ErrGameAlreadyVoided
+	}
+
+	week, err := GetWeekWithYear(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isWeekPast(week.Status, StatusActive) {
+		return nil, ErrWeekNotStarted
+	}
+
+	s, err := settings.GetForSeason(tx, week.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE public.games
+		SET voided_at = NOW(),
+			void_reason = $1,
+			voided_by = $2,
+			updated_at = NOW()
+		WHERE id = $3
+	`, reason, actingUserID, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	recalc, err := recalculateFromWeek(tx, week, s.PointsPerCorrectPick)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warn(
+		"game voided",
+		"game_id", gameID,
+		"week_id", week.ID,
+		"week_status", week.Status,
+		"reason", reason,
+		"voided_by", actingUserID,
+		"picks_updated", recalc.PicksUpdated,
+		"weeks_rescored", recalc.WeeksRescored,
+	)
+
+	return &VoidGameResult{
+		GameID:          gameID,
+		WeekID:          week.ID,
+		PicksUpdated:    recalc.PicksUpdated,
+		WeeksRescored:   recalc.WeeksRescored,
+		WeeksResnapshot: recalc.WeeksResnapshot,
+	}, nil
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// the league everything created before leagues belongs to, and the league used when a route doesn't name one
+const DefaultLeagueID = "00000000000000000000000000"
+
+// postgres foreign_key_violation
+const pgForeignKeyViolation = "23503"
+
+// league_members roles
+const (
+	LeagueRoleOwner        = "owner"        // manages members and who is a commissioner
+	LeagueRoleCommissioner = "commissioner" // runs the league's seasons
+	LeagueRoleMember       = "member"
+)
+
+var (
+	ErrLeagueNotFound        = errors.New("league not found")
+	ErrLeagueNameRequired    = errors.New("league name is required")
+	ErrLeagueMemberNotFound  = errors.New("user is not a member of this league")
+	ErrLeagueUserNotFound    = errors.New("user not found")
+	ErrInvalidLeagueRole     = errors.New("unknown league role")
+	ErrLastLeagueOwner       = errors.New("a league needs at least one owner")
+	ErrNotInLeague           = errors.New("resource is not in this league")
+	ErrUnknownLeagueResource = errors.New("unknown league resource")
+)
+
+// IsValidLeagueRole checks the role is one a league member can have
+func IsValidLeagueRole(role string) bool {
+	return role == LeagueRoleOwner || role == LeagueRoleCommissioner || role == LeagueRoleMember
+}
+
+// CreateLeague creates a league with the creating user as its owner
+func CreateLeague(db *sqlx.DB, name string, ownerID string) (*models.League, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrLeagueNameRequired
+	}
+
+	leagueID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var league models.League
+	err = tx.Get(&league, `
+		INSERT INTO public.leagues (id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING *
+	`, leagueID, name, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AddLeagueMember(tx, leagueID, ownerID, LeagueRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	league.Role = LeagueRoleOwner
+	return &league, nil
+}
+
+// GetLeague returns a league
+func GetLeague(q sqlx.Queryer, leagueID string) (*models.League, error) {
+	var league models.League
+	err := sqlx.Get(q, &league, `SELECT * FROM public.leagues WHERE id = $1`, leagueID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLeagueNotFound
+		}
+		return nil, err
+	}
+	return &league, nil
+}
+
+// GetUserLeagues lists the leagues a user is in with their role in each
+func GetUserLeagues(db *sqlx.DB, userID string) ([]models.League, error) {
+	leagues := []models.League{}
+	err := db.Select(&leagues, `
+		SELECT l.*, lm.role
+		FROM public.leagues l
+		JOIN public.league_members lm ON lm.league_id = l.id
+		WHERE lm.user_id = $1
+		ORDER BY l.name
+	`, userID)
+	return leagues, err
+}
+
+// GetLeagueMembers lists a league's members, owners and commissioners first
+func GetLeagueMembers(db *sqlx.DB, leagueID string) ([]models.LeagueMember, error) {
+	members := []models.LeagueMember{}
+	err := db.Select(&members, `
+		SELECT lm.league_id, lm.user_id, lm.role, lm.joined_at, p.username, p.avatar_url
+		FROM public.league_members lm
+		JOIN public.profiles p ON p.id = lm.user_id
+		WHERE lm.league_id = $1
+		ORDER BY
+			CASE lm.role WHEN 'owner' THEN 0 WHEN 'commissioner' THEN 1 ELSE 2 END,
+			p.username
+	`, leagueID)
+	return members, err
+}
+
+// AddLeagueMember adds a user to a league.  Users already in the league keep their current role
+func AddLeagueMember(e sqlx.Execer, leagueID string, userID string, role string) error {
+	if !IsValidLeagueRole(role) {
+		return ErrInvalidLeagueRole
+	}
+	_, err := e.Exec(`
+		INSERT INTO public.league_members (league_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (league_id, user_id) DO NOTHING
+	`, leagueID, userID, role)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrLeagueUserNotFound
+	}
+	return err
+}
+
+// UpdateLeagueMemberRole changes a member's role.  The last owner can't be demoted
+func UpdateLeagueMemberRole(db *sqlx.DB, leagueID string, userID string, role string) error {
+	if !IsValidLeagueRole(role) {
+		return ErrInvalidLeagueRole
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockLeagueMembers(tx, leagueID); err != nil {
+		return err
+	}
+	current, err := lockLeagueMember(tx, leagueID, userID)
+	if err != nil {
+		return err
+	}
+	if current == LeagueRoleOwner && role != LeagueRoleOwner {
+		if err := checkOtherOwner(tx, leagueID, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE public.league_members SET role = $3 WHERE league_id = $1 AND user_id = $2`, leagueID, userID, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveLeagueMember takes a user out of a league.  Their season history stays; the last owner can't leave
+func RemoveLeagueMember(db *sqlx.DB, leagueID string, userID string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockLeagueMembers(tx, leagueID); err != nil {
+		return err
+	}
+	current, err := lockLeagueMember(tx, leagueID, userID)
+	if err != nil {
+		return err
+	}
+	if current == LeagueRoleOwner {
+		if err := checkOtherOwner(tx, leagueID, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM public.league_members WHERE league_id = $1 AND user_id = $2`, leagueID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetLeagueSettings returns the league's own settings, or nil when it uses the global settings for everything
+func GetLeagueSettings(q sqlx.Queryer, leagueID string) (*models.LeagueSettings, error) {
+	var leagueSettings models.LeagueSettings
+	err := sqlx.Get(q, &leagueSettings, `SELECT * FROM public.league_settings WHERE league_id = $1`, leagueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &leagueSettings, nil
+}
+
+// SetLeagueSettings replaces the league's settings.  nil fields go back to the global settings.
+// Seasons already created keep their rules; new ones start with these
+func SetLeagueSettings(db *sqlx.DB, leagueID string, in models.LeagueSettings) (*models.LeagueSettings, error) {
+	var updated models.LeagueSettings
+	err := db.Get(&updated, `
+		INSERT INTO public.league_settings (
+			league_id, pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick, competition_timezone,
+			allow_commissioner_overrides, allow_picks_after_kickoff, pick_lock_rule
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (league_id) DO UPDATE
+		SET pick_cutoff_minutes = EXCLUDED.pick_cutoff_minutes,
+			allow_pick_edits = EXCLUDED.allow_pick_edits,
+			points_per_correct_pick = EXCLUDED.points_per_correct_pick,
+			competition_timezone = EXCLUDED.competition_timezone,
+			allow_commissioner_overrides = EXCLUDED.allow_commissioner_overrides,
+			allow_picks_after_kickoff = EXCLUDED.allow_picks_after_kickoff,
+			pick_lock_rule = EXCLUDED.pick_lock_rule
+		RETURNING *
+	`, leagueID, in.PickCutoffMinutes, in.AllowPickEdits, in.PointsPerCorrectPick, in.CompetitionTimezone,
+		in.AllowCommissionerOverrides, in.AllowPicksAfterKickoff, in.PickLockRule)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return nil, ErrLeagueNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// GetLeagueRole returns a user's role in a league, or "" if they aren't a member
+func GetLeagueRole(q sqlx.Queryer, leagueID string, userID string) (string, error) {
+	var role string
+	err := sqlx.Get(q, &role, `SELECT role FROM public.league_members WHERE league_id = $1 AND user_id = $2`, leagueID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// IsLeagueMember checks the user has any role in the league
+func IsLeagueMember(q sqlx.Queryer, leagueID string, userID string) (bool, error) {
+	role, err := GetLeagueRole(q, leagueID, userID)
+	return role != "", err
+}
+
+// LeagueNonMembers returns the user ids that aren't in the league, in the order given
+func LeagueNonMembers(q sqlx.Queryer, leagueID string, userIDs []string) ([]string, error) {
+	nonMembers := []string{}
+	for _, userID := range userIDs {
+		isMember, err := IsLeagueMember(q, leagueID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			nonMembers = append(nonMembers, userID)
+		}
+	}
+	return nonMembers, nil
+}
+
+// CanViewSeason checks the user can see a season: they're in its league, or have a role in the season itself
+func CanViewSeason(q sqlx.Queryer, seasonID string, userID string) (bool, error) {
+	var canView bool
+	err := sqlx.Get(q, &canView, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.seasons s
+			JOIN public.league_members lm ON lm.league_id = s.league_id
+			WHERE s.id = $1 AND lm.user_id = $2
+		) OR EXISTS (
+			SELECT 1
+			FROM public.season_roles
+			WHERE season_id = $1 AND user_id = $2
+		)
+	`, seasonID, userID)
+	return canView, err
+}
+
+// CheckLeagueResource makes sure a season, week or game (kind is the route param: season_id, week_id or
+// game_id) belongs to the league, so league scoped routes can't reach into another league
+func CheckLeagueResource(q sqlx.Queryer, leagueID string, kind string, resourceID string) error {
+	var query string
+	switch kind {
+	case "season_id":
+		query = `SELECT league_id FROM public.seasons WHERE id = $1`
+	case "week_id":
+		query = `SELECT s.league_id FROM public.weeks w JOIN public.seasons s ON s.id = w.season_id WHERE w.id = $1`
+	case "game_id":
+		query = `SELECT s.league_id FROM public.games g JOIN public.seasons s ON s.id = g.season_id WHERE g.id = $1`
+	default:
+		return ErrUnknownLeagueResource
+	}
+
+	var resourceLeagueID string
+	if err := sqlx.Get(q, &resourceLeagueID, query, resourceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotInLeague
+		}
+		return err
+	}
+	if resourceLeagueID != leagueID {
+		return ErrNotInLeague
+	}
+	return nil
+}
+
+// lockLeagueMembers locks the league so two role changes can't both take away "the other" owner
+func lockLeagueMembers(tx *sqlx.Tx, leagueID string) error {
+	var lockedID string
+	err := tx.Get(&lockedID, `SELECT id FROM public.leagues WHERE id = $1 FOR UPDATE`, leagueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLeagueNotFound
+	}
+	return err
+}
+
+// lockLeagueMember locks a member row and returns their role
+func lockLeagueMember(tx *sqlx.Tx, leagueID string, userID string) (string, error) {
+	var role string
+	err := tx.Get(&role, `SELECT role FROM public.league_members WHERE league_id = $1 AND user_id = $2 FOR UPDATE`, leagueID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrLeagueMemberNotFound
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+// checkOtherOwner makes sure someone besides userID owns the league
+func checkOtherOwner(tx *sqlx.Tx, leagueID string, userID string) error {
+	var owners int
+	err := tx.Get(&owners, `
+		SELECT COUNT(*)
+		FROM public.league_members
+		WHERE league_id = $1 AND role = 'owner' AND user_id <> $2
+	`, leagueID, userID)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastLeagueOwner
+	}
+	return nil
+}
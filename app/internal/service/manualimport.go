@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+var (
+	ErrNoManualGames    = errors.New("no games to import")
+	ErrManualImportRows = errors.New("some rows are invalid")
+)
+
+// columns a manual import CSV can have.  home_team, away_team and kickoff_time are required
+var manualImportColumns = []string{"home_team", "away_team", "kickoff_time", "home_spread", "total", "neutral_site"}
+
+// ManualGameInput is one uploaded game.  Teams can be abbreviations, full names ("Kansas City Chiefs") or any
+// team alias.  kickoff_time is RFC3339 (2025-09-07T13:00:00-04:00)
+type ManualGameInput struct {
+	HomeTeam    string   `json:"home_team"`
+	AwayTeam    string   `json:"away_team"`
+	KickoffTime string   `json:"kickoff_time"`
+	HomeSpread  *float64 `json:"home_spread"`
+	Total       *float64 `json:"total"`
+	NeutralSite bool     `json:"neutral_site"`
+}
+
+// ManualImportRowError is a problem with one uploaded row.  Rows are numbered from 1 (CSV rows after the header)
+type ManualImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+type ManualImportResult struct {
+	WeekID       string                 `json:"week_id"`
+	GamesCreated int                    `json:"games_created"`
+	SpreadsSet   int                    `json:"spreads_set"`
+	Status       string                 `json:"status"`
+	Errors       []ManualImportRowError `json:"errors,omitempty"`
+}
+
+// ParseManualGamesCSV reads a manual import CSV.  The first line is a header naming the columns (any order).
+// Values that can't be parsed come back as row errors (the row is still returned, without that value)
+func ParseManualGamesCSV(r io.Reader) ([]ManualGameInput, []ManualImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrNoManualGames
+		}
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isManualImportColumn(name) {
+			return nil, nil, fmt.Errorf("unknown column %q, columns are %s", name, strings.Join(manualImportColumns, ", "))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"home_team", "away_team", "kickoff_time"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	var games []ManualGameInput
+	var rowErrors []ManualImportRowError
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		game := ManualGameInput{
+			HomeTeam:    value("home_team"),
+			AwayTeam:    value("away_team"),
+			KickoffTime: value("kickoff_time"),
+		}
+
+		for _, field := range []struct {
+			column string
+			target **float64
+		}{
+			{"home_spread", &game.HomeSpread},
+			{"total", &game.Total},
+		} {
+			raw := value(field.column)
+			if raw == "" {
+				continue
+			}
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				rowErrors = append(rowErrors, ManualImportRowError{Row: row, Field: field.column, Error: "not a number"})
+				continue
+			}
+			*field.target = &number
+		}
+
+		if raw := value("neutral_site"); raw != "" {
+			neutral, err := strconv.ParseBool(raw)
+			if err != nil {
+				rowErrors = append(rowErrors, ManualImportRowError{Row: row, Field: "neutral_site", Error: "must be true or false"})
+			}
+			game.NeutralSite = neutral
+		}
+
+		games = append(games, game)
+	}
+
+	if len(games) == 0 {
+		return nil, nil, ErrNoManualGames
+	}
+	return games, rowErrors, nil
+}
+
+func isManualImportColumn(name string) bool {
+	for _, column := range manualImportColumns {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportManualGames adds uploaded games to a week, for games a provider is missing (international or flexed
+// games).  It's all or nothing: every row is validated first and if any row has a problem nothing is written
+// and the result lists every row error (with ErrManualImportRows).
+// A draft week moves to games_imported, and any uploaded spread moves the week to spreads_set, the same
+// transitions as importing games and setting spreads.  Weeks that are already active can't be changed
+func ImportManualGames(ctx context.Context, db *sqlx.DB, weekID string, games []ManualGameInput, parseErrors []ManualImportRowError, actingUserID string) (*ManualImportResult, error) {
+	if len(games) == 0 {
+		return nil, ErrNoManualGames
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	week, err := lockWeekForGameEdit(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	// teams already playing this week, so a game (or a team) isn't added twice
+	var existing []struct {
+		HomeTeamID string `db:"home_team_id"`
+		AwayTeamID string `db:"away_team_id"`
+	}
+	err = tx.Select(&existing, `SELECT home_team_id, away_team_id FROM public.games WHERE week_id = $1`, weekID)
+	if err != nil {
+		return nil, err
+	}
+	teamsPlaying := make(map[string]bool)
+	for _, game := range existing {
+		teamsPlaying[game.HomeTeamID] = true
+		teamsPlaying[game.AwayTeamID] = true
+	}
+
+	type validatedGame struct {
+		KickoffTime  time.Time
+		HomeTeamID   string
+		AwayTeamID   string
+		HomeTeamAbbr string
+		AwayTeamAbbr string
+		HomeSpread   *float64
+		Total        *float64
+		NeutralSite  bool
+	}
+
+	rowErrors := append([]ManualImportRowError{}, parseErrors...)
+	validated := make([]validatedGame, 0, len(games))
+
+	for i, game := range games {
+		row := i + 1
+		addError := func(field string, message string) {
+			rowErrors = append(rowErrors, ManualImportRowError{Row: row, Field: field, Error: message})
+		}
+
+		homeTeamID, homeAbbr, err := manualImportTeam(tx, game.HomeTeam)
+		if err != nil {
+			if !errors.Is(err, ErrTeamMappingFailed) {
+				return nil, err
+			}
+			addError("home_team", fmt.Sprintf("unknown team %q", game.HomeTeam))
+		}
+		awayTeamID, awayAbbr, err := manualImportTeam(tx, game.AwayTeam)
+		if err != nil {
+			if !errors.Is(err, ErrTeamMappingFailed) {
+				return nil, err
+			}
+			addError("away_team", fmt.Sprintf("unknown team %q", game.AwayTeam))
+		}
+
+		if homeTeamID != "" && homeTeamID == awayTeamID {
+			addError("away_team", "a team can't play itself")
+		}
+		for _, team := range []struct{ field, teamID, abbr string }{
+			{"home_team", homeTeamID, homeAbbr},
+			{"away_team", awayTeamID, awayAbbr},
+		} {
+			if team.teamID != "" && teamsPlaying[team.teamID] {
+				addError(team.field, fmt.Sprintf("%s already has a game this week", team.abbr))
+			}
+		}
+
+		kickoff, err := time.Parse(time.RFC3339, game.KickoffTime)
+		if err != nil {
+			addError("kickoff_time", "must be an RFC3339 time like 2025-09-07T13:00:00-04:00")
+		}
+
+		if game.HomeSpread != nil && !isHalfPoint(*game.HomeSpread) {
+			addError("home_spread", "spreads must be multiples of 0.5")
+		}
+		if game.Total != nil && (*game.Total < 0 || !isHalfPoint(*game.Total)) {
+			addError("total", "totals must be positive multiples of 0.5")
+		}
+
+		if homeTeamID != "" && awayTeamID != "" {
+			teamsPlaying[homeTeamID] = true
+			teamsPlaying[awayTeamID] = true
+		}
+
+		validated = append(validated, validatedGame{
+			KickoffTime:  kickoff,
+			HomeTeamID:   homeTeamID,
+			AwayTeamID:   awayTeamID,
+			HomeTeamAbbr: homeAbbr,
+			AwayTeamAbbr: awayAbbr,
+			HomeSpread:   game.HomeSpread,
+			Total:        game.Total,
+			NeutralSite:  game.NeutralSite,
+		})
+	}
+
+	result := &ManualImportResult{
+		WeekID: weekID,
+		Status: week.Status,
+	}
+
+	if len(rowErrors) > 0 {
+		result.Errors = rowErrors
+		return result, ErrManualImportRows
+	}
+
+	for _, g := range validated {
+		gameID, err := id.New()
+		if err != nil {
+			return nil, err
+		}
+
+		// no external_game_id.  Scores still import by matchup if the provider has the game
+		var created models.Game
+		err = tx.Get(&created, `
+			INSERT INTO public.games (
+				id,
+				week_id,
+				season_id,
+				kickoff_time,
+				home_team_id,
+				away_team_id,
+				home_team_abbr,
+				away_team_abbr,
+				created_by,
+				home_spread,
+				total,
+				neutral_site,
+				status
+			)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+			RETURNING *
+		`,
+			gameID,
+			weekID,
+			week.SeasonID,
+			g.KickoffTime,
+			g.HomeTeamID,
+			g.AwayTeamID,
+			g.HomeTeamAbbr,
+			g.AwayTeamAbbr,
+			actingUserID,
+			g.HomeSpread,
+			g.Total,
+			g.NeutralSite,
+			GameStatusScheduled,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := writeGameAudit(tx, GameAuditCreate, &created, nil, &created, actingUserID); err != nil {
+			return nil, err
+		}
+
+		result.GamesCreated++
+		if g.HomeSpread != nil {
+			result.SpreadsSet++
+		}
+	}
+
+	// same transitions as importing games and then setting spreads
+	newStatus := week.Status
+	if newStatus == StatusDraft {
+		newStatus = StatusGamesImported
+	}
+	if result.SpreadsSet > 0 {
+		newStatus = StatusSpreadsSet
+	}
+	if newStatus != week.Status {
+		if err := UpdateWeekStatus(tx, weekID, newStatus); err != nil {
+			return nil, err
+		}
+	}
+	result.Status = newStatus
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Info(
+		"manually imported games",
+		"week_id", weekID,
+		"games_created", result.GamesCreated,
+		"spreads_set", result.SpreadsSet,
+		"status", newStatus,
+		"imported_by", actingUserID,
+	)
+
+	return result, nil
+}
+
+// manualImportTeam finds a team by abbreviation, full name or alias.  The Odds API aliases are full team names
+// so they're included
+func manualImportTeam(q sqlx.Queryer, name string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", ErrTeamMappingFailed
+	}
+
+	teamID, err := GetTeamIDByAbbreviation(q, external.ProviderOdds, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	var abbreviation string
+	if err := sqlx.Get(q, &abbreviation, `SELECT abbreviation FROM public.teams WHERE id = $1`, teamID); err != nil {
+		return "", "", err
+	}
+	return teamID, abbreviation, nil
+}
+
+// isHalfPoint checks a spread or total is a multiple of 0.5
+func isHalfPoint(value float64) bool {
+	return math.Abs(value*2-math.Round(value*2)) < 0.001
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+var (
+	ErrOverridesDisabled     = errors.New("commissioner overrides are turned off")
+	ErrWeekNotOverridable    = errors.New("picks can only be overridden while the week is active or played")
+	ErrUserNotParticipant    = errors.New("user is not a participant in this season")
+	ErrInvalidPickOverride   = errors.New("invalid pick")
+	ErrOverrideReasonMissing = errors.New("a reason is required to override a pick")
+)
+
+// PickOverride sets (or with a nil SelectedTeamID clears) a user's pick for one game
+type PickOverride struct {
+	GameID         string  `json:"game_id" binding:"required"`
+	SelectedTeamID *string `json:"selected_team_id"`
+	Confidence     *int    `json:"confidence"`
+	TotalPick      *string `json:"total_pick"`
+	Reason         string  `json:"reason" binding:"required"`
+}
+
+// OverridePick lets a commissioner set or clear a pick for a user, e.g. someone who texted their picks in.
+// Kickoff times and the pick cutoff don't apply, but the week can't have been graded yet.  The pick is
+// locked and records who overrode it so it's visible to everyone and the user can't change it back
+func OverridePick(ctx context.Context, db *sqlx.DB, weekID string, userID string, override PickOverride, actingUserID string) (*models.Pick, error) {
+	s, err := settings.GetForWeek(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.AllowCommissionerOverrides {
+		return nil, ErrOverridesDisabled
+	}
+	if override.Reason == "" {
+		return nil, ErrOverrideReasonMissing
+	}
+
+	week, err := GetWeekWithYear(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+	if !isWeekPast(week.Status, StatusActive) || isWeekPast(week.Status, StatusPicksResultsCalculated) {
+		return nil, ErrWeekNotOverridable
+	}
+
+	isParticipant, err := IsUserSeasonParticipant(db, weekID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, ErrUserNotParticipant
+	}
+
+	// a cleared pick has nothing else on it
+	if override.SelectedTeamID == nil {
+		override.Confidence = nil
+		override.TotalPick = nil
+	}
+	if week.ScoringMode != ScoringModeConfidence {
+		override.Confidence = nil
+	}
+	if !week.TotalsEnabled {
+		override.TotalPick = nil
+	}
+	if override.TotalPick != nil && *override.TotalPick != TotalPickOver && *override.TotalPick != TotalPickUnder {
+		return nil, fmt.Errorf("%w: total pick must be over or under", ErrInvalidPickOverride)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the week status can't move out from under the override
+	var status string
+	if err := tx.Get(&status, `SELECT status FROM public.weeks WHERE id = $1 FOR SHARE`, weekID); err != nil {
+		return nil, err
+	}
+	if !isWeekPast(status, StatusActive) || isWeekPast(status, StatusPicksResultsCalculated) {
+		return nil, ErrWeekNotOverridable
+	}
+
+	var game struct {
+		HomeTeamID string `db:"home_team_id"`
+		AwayTeamID string `db:"away_team_id"`
+		Voided     bool   `db:"voided"`
+	}
+	err = tx.Get(&game, `
+		SELECT home_team_id, away_team_id, voided_at IS NOT NULL AS voided
+		FROM public.games
+		WHERE id = $1 AND week_id = $2
+		FOR SHARE
+	`, override.GameID, weekID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+	if game.Voided {
+		return nil, fmt.Errorf("%w: game has been voided", ErrInvalidPickOverride)
+	}
+	if team := override.SelectedTeamID; team != nil && *team != game.HomeTeamID && *team != game.AwayTeamID {
+		return nil, fmt.Errorf("%w: selected team does not belong to this game", ErrInvalidPickOverride)
+	}
+
+	if week.ScoringMode == ScoringModeConfidence && override.SelectedTeamID != nil {
+		if err := checkOverrideConfidence(tx, weekID, userID, override); err != nil {
+			return nil, err
+		}
+	}
+
+	if week.SeasonType == SeasonTypeSurvivor && override.SelectedTeamID != nil {
+		used, err := IsSurvivorTeamUsed(tx, week.SeasonID, weekID, userID, *override.SelectedTeamID)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, fmt.Errorf("%w: team was already used in an earlier week", ErrInvalidPickOverride)
+		}
+
+		// survivor users get one pick a week, so the override replaces whatever else they picked
+		if _, err := tx.Exec(`DELETE FROM public.picks WHERE user_id = $1 AND week_id = $2 AND game_id <> $3`, userID, weekID, override.GameID); err != nil {
+			return nil, err
+		}
+	}
+
+	pickID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	var pick models.Pick
+	err = tx.Get(&pick, `
+		INSERT INTO public.picks (
+			id, user_id, game_id, week_id, selected_team_id, confidence, total_pick,
+			user_locked_at, overridden_by, overridden_at, override_reason
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::public.total_pick, NOW(), $8, NOW(), $9)
+		ON CONFLICT (user_id, game_id)
+		DO UPDATE
+		SET selected_team_id = EXCLUDED.selected_team_id,
+			confidence = EXCLUDED.confidence,
+			total_pick = EXCLUDED.total_pick,
+			user_locked_at = COALESCE(picks.user_locked_at, EXCLUDED.user_locked_at),
+			overridden_by = EXCLUDED.overridden_by,
+			overridden_at = EXCLUDED.overridden_at,
+			override_reason = EXCLUDED.override_reason
+		RETURNING *
+	`, pickID, userID, override.GameID, weekID, override.SelectedTeamID, override.Confidence, override.TotalPick, actingUserID, override.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warn(
+		"pick overridden",
+		"week_id", weekID,
+		"game_id", override.GameID,
+		"user_id", userID,
+		"cleared", override.SelectedTeamID == nil,
+		"reason", override.Reason,
+		"overridden_by", actingUserID,
+	)
+
+	return &pick, nil
+}
+
+// checkOverrideConfidence makes sure an overridden confidence pick has a confidence that's in range and not
+// used by the user's other picks this week
+func checkOverrideConfidence(tx *sqlx.Tx, weekID string, userID string, override PickOverride) error {
+	if override.Confidence == nil {
+		return fmt.Errorf("%w: every pick needs a confidence value", ErrInvalidPickOverride)
+	}
+
+	var games int
+	if err := tx.Get(&games, `SELECT COUNT(*) FROM public.games WHERE week_id = $1`, weekID); err != nil {
+		return err
+	}
+	if *override.Confidence < 1 || *override.Confidence > games {
+		return fmt.Errorf("%w: confidence must be between 1 and %d", ErrInvalidPickOverride, games)
+	}
+
+	var taken bool
+	err := tx.Get(&taken, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.picks
+			WHERE user_id = $1 AND week_id = $2 AND game_id <> $3 AND confidence = $4
+		)
+	`, userID, weekID, override.GameID, *override.Confidence)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: confidence %d is already used by another pick", ErrInvalidPickOverride, *override.Confidence)
+	}
+	return nil
+}
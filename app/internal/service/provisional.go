@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+// Provisional pick results
+const (
+	ProvisionalCorrect   = "correct"
+	ProvisionalIncorrect = "incorrect"
+	ProvisionalPush      = "push"
+	ProvisionalPending   = "pending" // game hasn't started (or has no score yet)
+	ProvisionalVoid      = "void"
+)
+
+var (
+	ErrWeekNotLive = errors.New("provisional results are only available for active or played weeks")
+)
+
+// ProvisionalPick is a single pick graded as if its game ended with the current score
+type ProvisionalPick struct {
+	GameID         string  `json:"game_id"`
+	SelectedTeamID *string `json:"selected_team_id"`
+	Confidence     *int    `json:"confidence,omitempty"`
+	Result         string  `json:"result"`
+	TotalPick      *string `json:"total_pick,omitempty"`
+	TotalResult    string  `json:"total_result,omitempty"`
+}
+
+// ProvisionalStanding is a single participant's provisional week score
+type ProvisionalStanding struct {
+	UserID    string            `json:"user_id"`
+	Username  string            `json:"username"`
+	AvatarURL *string           `json:"avatar_url"`
+	Points    float64           `json:"points"`
+	Rank      int               `json:"rank"`
+	Correct   int               `json:"correct"`
+	Incorrect int               `json:"incorrect"`
+	Pushes    int               `json:"pushes"`
+	Pending   int               `json:"pending"`
+	Alive     *bool             `json:"alive,omitempty"` // survivor seasons only
+	Picks     []ProvisionalPick `json:"picks"`
+}
+
+type ProvisionalResults struct {
+	WeekID    string                `json:"week_id"`
+	Status    string                `json:"status"`
+	Standings []ProvisionalStanding `json:"standings"`
+}
+
+// GetProvisionalResults grades every pick in an active week as if the games ended with their current scores,
+// and ranks participants the same way CalculateWeekPoints would.  Nothing is written; week_results only
+// ever comes from the real calculation.
+// Only picks on games that have started are included so nobody can see picks early.
+func GetProvisionalResults(db *sqlx.DB, weekID string) (*ProvisionalResults, error) {
+	week, err := GetWeekWithYear(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	if week.Status != StatusActive && week.Status != StatusPlayed {
+		return nil, ErrWeekNotLive
+	}
+
+	s, err := settings.GetForWeek(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	// straight-up survivor seasons ignore the spread
+	winningTeam := WinningTeamByGame
+	usesSpread := true
+	if week.SeasonType == SeasonTypeSurvivor && !week.SurvivorUseSpread {
+		winningTeam = WinningTeamStraightUp
+		usesSpread = false
+	}
+
+	games, err := GetWeekGames(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	picks, err := GetWeekPicks(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	type participant struct {
+		UserID     string  `db:"user_id"`
+		Username   string  `db:"username"`
+		AvatarURL  *string `db:"avatar_url"`
+		Eliminated bool    `db:"eliminated"` // already eliminated in an earlier survivor week
+	}
+	var participants []participant
+	err = db.Select(&participants, `
+		SELECT
+			sp.user_id,
+			COALESCE(p.username, '') AS username,
+			p.avatar_url,
+			COALESCE(ew.number < $2, false) AS eliminated
+		FROM public.season_participants sp
+		LEFT JOIN public.profiles p ON p.id = sp.user_id
+		LEFT JOIN public.weeks ew ON ew.id = sp.eliminated_week_id
+		WHERE sp.season_id = $1
+	`, week.SeasonID, week.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	gamesByID := make(map[string]models.Game)
+	for _, game := range games {
+		gamesByID[game.ID] = game
+	}
+
+	picksByUserID := make(map[string][]models.Pick)
+	for _, pick := range picks {
+		picksByUserID[pick.UserID] = append(picksByUserID[pick.UserID], pick)
+	}
+
+	multiplier := PushPointsMultiplier(week.PushPolicy)
+
+	standings := make([]ProvisionalStanding, len(participants))
+	for i, p := range participants {
+		st := &standings[i]
+		st.UserID = p.UserID
+		st.Username = p.Username
+		st.AvatarURL = p.AvatarURL
+		st.Picks = []ProvisionalPick{}
+		survived := false
+
+		for _, pick := range picksByUserID[st.UserID] {
+			game, ok := gamesByID[pick.GameID]
+			if !ok || !gameStarted(game) {
+				// keep picks hidden until kickoff, but survivors with a pending pick are still alive
+				if pick.SelectedTeamID != nil {
+					st.Pending++
+					survived = true
+				}
+				continue
+			}
+
+			pp := ProvisionalPick{
+				GameID:         pick.GameID,
+				SelectedTeamID: pick.SelectedTeamID,
+				Confidence:     pick.Confidence,
+				TotalPick:      pick.TotalPick,
+			}
+
+			base := float64(s.PointsPerCorrectPick)
+			if week.ScoringMode == ScoringModeConfidence && pick.Confidence != nil {
+				base = float64(*pick.Confidence)
+			}
+
+			switch {
+			case game.VoidedAt != nil, usesSpread && game.HomeSpread == nil:
+				// games without a spread are graded as void, same as gradeWeekPicks
+				pp.Result = ProvisionalVoid
+				survived = survived || pick.SelectedTeamID != nil
+			case game.HomeScore == nil || game.AwayScore == nil:
+				pp.Result = ProvisionalPending
+				st.Pending++
+				survived = survived || pick.SelectedTeamID != nil
+			default:
+				isCorrect, isPush := gradePick(pick.SelectedTeamID, winningTeam(game), week.PushPolicy)
+				pp.Result, st.Points = provisionalResult(isCorrect, isPush, base, multiplier, st.Points)
+				switch pp.Result {
+				case ProvisionalCorrect:
+					st.Correct++
+				case ProvisionalIncorrect:
+					st.Incorrect++
+				case ProvisionalPush:
+					st.Pushes++
+				}
+				// same rule as ApplySurvivorEliminations: anything but a graded loss survives
+				survived = survived || (pick.SelectedTeamID != nil && (isCorrect == nil || *isCorrect))
+
+				if week.TotalsEnabled && game.Total != nil && pick.TotalPick != nil {
+					totalIsCorrect, totalIsPush := gradePick(pick.TotalPick, TotalResultByGame(game), week.PushPolicy)
+					pp.TotalResult, st.Points = provisionalResult(totalIsCorrect, totalIsPush, float64(s.PointsPerCorrectPick), multiplier, st.Points)
+				}
+			}
+
+			st.Picks = append(st.Picks, pp)
+		}
+
+		// survivor seasons score 1 for surviving the week, like CalculateWeekPoints
+		if week.SeasonType == SeasonTypeSurvivor {
+			alive := !p.Eliminated && survived
+			st.Alive = &alive
+			st.Points = 0
+			if alive {
+				st.Points = 1
+			}
+		}
+	}
+
+	// rank like week_results: ties share a rank.  There's no tiebreaker until the last game is final
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		return standings[i].Username < standings[j].Username
+	})
+	for i := range standings {
+		if i > 0 && standings[i].Points == standings[i-1].Points {
+			standings[i].Rank = standings[i-1].Rank
+		} else {
+			standings[i].Rank = i + 1
+		}
+	}
+
+	return &ProvisionalResults{
+		WeekID:    weekID,
+		Status:    week.Status,
+		Standings: standings,
+	}, nil
+}
+
+// gameStarted returns true once a game has a live or final status (or was voided after the provider reported it)
+func gameStarted(game models.Game) bool {
+	switch game.Status {
+	case GameStatusInProgress, GameStatusFinal, GameStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// provisionalResult turns a graded pick into a result and adds its points the same way CalculateWeekPoints does
+func provisionalResult(isCorrect *bool, isPush bool, base float64, pushMultiplier float64, points float64) (string, float64) {
+	switch {
+	case isCorrect != nil && *isCorrect:
+		if isPush {
+			return ProvisionalPush, points + base
+		}
+		return ProvisionalCorrect, points + base
+	case isCorrect != nil:
+		if isPush {
+			return ProvisionalPush, points
+		}
+		return ProvisionalIncorrect, points
+	case isPush:
+		return ProvisionalPush, points + base*pushMultiplier
+	}
+	// no pick at all
+	return ProvisionalIncorrect, points
+}
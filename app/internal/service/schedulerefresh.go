@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+var (
+	ErrScheduleNotRefreshable = errors.New("schedules can only be refreshed until the week has been played")
+	ErrScheduleChangeLocked   = errors.New("games can't be added or given new teams once the week is active")
+)
+
+// ScheduleChange is one difference between our games and the provider's schedule
+type ScheduleChange struct {
+	GameID          string     `json:"game_id,omitempty"`
+	ExternalGameID  *int64     `json:"external_game_id,omitempty"`
+	Matchup         string     `json:"matchup"`
+	PreviousMatchup string     `json:"previous_matchup,omitempty"`
+	KickoffTime     *time.Time `json:"kickoff_time,omitempty"`
+	PreviousKickoff *time.Time `json:"previous_kickoff,omitempty"`
+	HasSpread       bool       `json:"has_spread"`
+	Picks           int        `json:"picks"`
+	// destructive changes (new teams for a game, removed games) wait for the commissioner to confirm
+	NeedsConfirm bool `json:"needs_confirm"`
+	Applied      bool `json:"applied"`
+}
+
+type ScheduleRefreshResult struct {
+	WeekID          string           `json:"week_id"`
+	Provider        string           `json:"provider"`
+	Status          string           `json:"status"`
+	Added           []ScheduleChange `json:"added"`
+	Changed         []ScheduleChange `json:"changed"`
+	Removed         []ScheduleChange `json:"removed"`
+	Unchanged       int              `json:"unchanged"`
+	ConfirmRequired bool             `json:"confirm_required"` // there are changes that weren't applied without confirm
+
+	// active weeks only: new games and new teams the provider has that can't be applied because spreads can't be
+	// set anymore.  Returned with ErrScheduleChangeLocked, and nothing is applied
+	Locked []ScheduleChange `json:"locked,omitempty"`
+}
+
+// RefreshWeekSchedule updates a week's games from the games provider without starting over.  Games are matched by
+// external_game_id (or by matchup for games added by hand), so spreads and picks stay put.
+//
+// New games are added and kickoff times are updated (flexed games) right away.  Changes that would touch spreads or
+// picks are only reported unless confirm is true:
+//   - a game whose teams changed gets the new teams, its spread and total are cleared and its picks deleted
+//   - a game the provider no longer has is deleted along with its picks
+//
+// Games that have kicked off are left alone.  Allowed from draft through active; every applied change is
+// written to the game audit log.  Once the week is active spreads can't be set, so a new game or new teams for
+// a game would leave a game without a spread to grade against.  Those are returned in Locked with
+// ErrScheduleChangeLocked and nothing is applied; the commissioner has to revert the week to fix them
+func RefreshWeekSchedule(ctx context.Context, db *sqlx.DB, weekID string, confirm bool, actingUserID string) (*ScheduleRefreshResult, error) {
+	week, err := GetWeekWithYear(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+	if isWeekPast(week.Status, StatusPlayed) {
+		return nil, ErrScheduleNotRefreshable
+	}
+
+	provider, err := external.NewGamesProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	providerGames, err := provider.FetchWeekGames(ctx, week.Year, week.Number, week.IsPostseason)
+	if err != nil {
+		return nil, err
+	}
+	if len(providerGames) == 0 {
+		// an empty schedule is almost certainly a provider problem, not every game being removed
+		return nil, fmt.Errorf("games provider %s returned 0 games for week %d (year %d, postseason=%v)", provider.Name(), week.Number, week.Year, week.IsPostseason)
+	}
+
+	if err := resolveTeamAbbreviations(db, provider.Name(), providerGames); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the status may have moved while we were waiting on the provider
+	var status string
+	if err := tx.Get(&status, `SELECT status FROM public.weeks WHERE id = $1 FOR UPDATE`, weekID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWeekNotFound
+		}
+		return nil, err
+	}
+	if isWeekPast(status, StatusPlayed) {
+		return nil, ErrScheduleNotRefreshable
+	}
+
+	var existing []models.Game
+	if err := tx.Select(&existing, `SELECT * FROM public.games WHERE week_id = $1 ORDER BY kickoff_time FOR UPDATE`, weekID); err != nil {
+		return nil, err
+	}
+
+	picksByGame, err := weekPickCounts(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	byExternalID := make(map[string]*models.Game)
+	byMatchup := make(map[string]*models.Game)
+	for i := range existing {
+		game := &existing[i]
+		if game.ExternalGameID != nil {
+			byExternalID[*game.ExternalGameID] = game
+		} else {
+			byMatchup[game.AwayTeamAbbr+"@"+game.HomeTeamAbbr] = game
+		}
+	}
+
+	result := &ScheduleRefreshResult{
+		WeekID:   weekID,
+		Provider: provider.Name(),
+		Added:    []ScheduleChange{},
+		Changed:  []ScheduleChange{},
+		Removed:  []ScheduleChange{},
+	}
+	matched := make(map[string]bool)
+	now := time.Now()
+	locked := isWeekPast(status, StatusActive)
+
+	for _, pg := range providerGames {
+		externalID := pg.ExternalID
+		matchup := pg.AwayTeamAbbr + " @ " + pg.HomeTeamAbbr
+
+		game, ok := byExternalID[strconv.FormatInt(pg.ExternalID, 10)]
+		if !ok {
+			game, ok = byMatchup[pg.AwayTeamAbbr+"@"+pg.HomeTeamAbbr]
+		}
+
+		// a game we don't have yet
+		if !ok {
+			if locked {
+				kickoff := pg.KickoffTime
+				result.Locked = append(result.Locked, ScheduleChange{
+					ExternalGameID: &externalID,
+					Matchup:        matchup,
+					KickoffTime:    &kickoff,
+				})
+				continue
+			}
+			created, err := insertProviderGame(tx, week, pg, actingUserID)
+			if err != nil {
+				return nil, err
+			}
+			kickoff := pg.KickoffTime
+			result.Added = append(result.Added, ScheduleChange{
+				GameID:         created.ID,
+				ExternalGameID: &externalID,
+				Matchup:        matchup,
+				KickoffTime:    &kickoff,
+				Applied:        true,
+			})
+			continue
+		}
+		matched[game.ID] = true
+
+		// leave games that have started alone, the score import owns them now
+		if game.Status != GameStatusScheduled || game.KickoffTime.Before(now) {
+			result.Unchanged++
+			continue
+		}
+
+		teamsChanged := game.HomeTeamAbbr != pg.HomeTeamAbbr || game.AwayTeamAbbr != pg.AwayTeamAbbr
+		kickoffChanged := !game.KickoffTime.Equal(pg.KickoffTime)
+		linkExternalID := game.ExternalGameID == nil
+
+		if !teamsChanged && !kickoffChanged && !linkExternalID {
+			result.Unchanged++
+			continue
+		}
+
+		change := ScheduleChange{
+			GameID:         game.ID,
+			ExternalGameID: &externalID,
+			Matchup:        matchup,
+			HasSpread:      game.HomeSpread != nil,
+			Picks:          picksByGame[game.ID],
+			NeedsConfirm:   teamsChanged,
+		}
+		if teamsChanged {
+			change.PreviousMatchup = game.AwayTeamAbbr + " @ " + game.HomeTeamAbbr
+		}
+		if kickoffChanged {
+			kickoff, previous := pg.KickoffTime, game.KickoffTime
+			change.KickoffTime = &kickoff
+			change.PreviousKickoff = &previous
+		}
+
+		if teamsChanged && locked {
+			result.Locked = append(result.Locked, change)
+			continue
+		}
+
+		if !teamsChanged || confirm {
+			if err := applyScheduleChange(tx, game, pg, teamsChanged, actingUserID); err != nil {
+				return nil, err
+			}
+			change.Applied = true
+		}
+		if !change.Applied && change.NeedsConfirm {
+			result.ConfirmRequired = true
+		}
+		// a game added by hand just getting its external id linked isn't worth reporting
+		if teamsChanged || kickoffChanged {
+			result.Changed = append(result.Changed, change)
+		} else {
+			result.Unchanged++
+		}
+	}
+
+	// games with an external id that the provider doesn't have anymore.  Games added by hand are ours to manage
+	for i := range existing {
+		game := &existing[i]
+		if matched[game.ID] || game.ExternalGameID == nil {
+			continue
+		}
+		if game.Status != GameStatusScheduled || game.KickoffTime.Before(now) {
+			continue
+		}
+
+		kickoff := game.KickoffTime
+		change := ScheduleChange{
+			GameID:       game.ID,
+			Matchup:      game.AwayTeamAbbr + " @ " + game.HomeTeamAbbr,
+			KickoffTime:  &kickoff,
+			HasSpread:    game.HomeSpread != nil,
+			Picks:        picksByGame[game.ID],
+			NeedsConfirm: true,
+		}
+		if externalID, err := strconv.ParseInt(*game.ExternalGameID, 10, 64); err == nil {
+			change.ExternalGameID = &externalID
+		}
+
+		if confirm {
+			if _, err := tx.Exec(`DELETE FROM public.games WHERE id = $1`, game.ID); err != nil {
+				return nil, err
+			}
+			if err := writeGameAudit(tx, GameAuditDelete, game, game, nil, actingUserID); err != nil {
+				return nil, err
+			}
+			change.Applied = true
+		} else {
+			result.ConfirmRequired = true
+		}
+		result.Removed = append(result.Removed, change)
+	}
+
+	if len(result.Locked) > 0 {
+		return result, ErrScheduleChangeLocked
+	}
+
+	result.Status = status
+	if status == StatusDraft && len(result.Added) > 0 {
+		if err := UpdateWeekStatus(tx, weekID, StatusGamesImported); err != nil {
+			return nil, err
+		}
+		result.Status = StatusGamesImported
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Info(
+		"week schedule refreshed",
+		"week_id", weekID,
+		"provider", provider.Name(),
+		"added", len(result.Added),
+		"changed", len(result.Changed),
+		"removed", len(result.Removed),
+		"confirmed", confirm,
+		"refreshed_by", actingUserID,
+	)
+
+	return result, nil
+}
+
+// insertProviderGame adds a provider game to the week without a spread.  Abbreviations have already been
+// resolved through the provider's aliases
+func insertProviderGame(tx *sqlx.Tx, week *models.WeekWithYear, pg external.ProviderGame, actingUserID string) (*models.Game, error) {
+	homeTeamID, err := GetTeamIDByAbbreviation(tx, TeamAliasProviderAny, pg.HomeTeamAbbr)
+	if err != nil {
+		return nil, err
+	}
+	awayTeamID, err := GetTeamIDByAbbreviation(tx, TeamAliasProviderAny, pg.AwayTeamAbbr)
+	if err != nil {
+		return nil, err
+	}
+
+	gameID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	var game models.Game
+	err = tx.Get(&game, `
+		INSERT INTO public.games (
+			id,
+			week_id,
+			season_id,
+			external_game_id,
+			kickoff_time,
+			home_team_id,
+			away_team_id,
+			home_team_abbr,
+			away_team_abbr,
+			created_by,
+			home_spread,
+			neutral_site,
+			status
+		)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,NULL,FALSE,$11)
+		RETURNING *
+	`,
+		gameID,
+		week.ID,
+		week.SeasonID,
+		pg.ExternalID,
+		pg.KickoffTime,
+		homeTeamID,
+		awayTeamID,
+		pg.HomeTeamAbbr,
+		pg.AwayTeamAbbr,
+		actingUserID,
+		GameStatusScheduled,
+	)
+	if err != nil {
+		return nil, gameWriteError(err)
+	}
+
+	if err := writeGameAudit(tx, GameAuditCreate, &game, nil, &game, actingUserID); err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// applyScheduleChange updates a game's kickoff time and external id from the provider.  When the teams changed
+// the teams are replaced too, and the spread, total and picks are cleared since they were for a different matchup
+func applyScheduleChange(tx *sqlx.Tx, game *models.Game, pg external.ProviderGame, teamsChanged bool, actingUserID string) error {
+	homeTeamID, awayTeamID := game.HomeTeamID, game.AwayTeamID
+	if teamsChanged {
+		var err error
+		if homeTeamID, err = GetTeamIDByAbbreviation(tx, TeamAliasProviderAny, pg.HomeTeamAbbr); err != nil {
+			return err
+		}
+		if awayTeamID, err = GetTeamIDByAbbreviation(tx, TeamAliasProviderAny, pg.AwayTeamAbbr); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM public.picks WHERE game_id = $1`, game.ID); err != nil {
+			return err
+		}
+	}
+
+	var updated models.Game
+	err := tx.Get(&updated, `
+		UPDATE public.games
+		SET kickoff_time = $2,
+			external_game_id = $3,
+			home_team_id = $4,
+			away_team_id = $5,
+			home_team_abbr = $6,
+			away_team_abbr = $7,
+			home_spread = CASE WHEN $8 THEN NULL ELSE home_spread END,
+			total = CASE WHEN $8 THEN NULL ELSE total END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`, game.ID, pg.KickoffTime, pg.ExternalID, homeTeamID, awayTeamID, pg.HomeTeamAbbr, pg.AwayTeamAbbr, teamsChanged)
+	if err != nil {
+		return gameWriteError(err)
+	}
+
+	return writeGameAudit(tx, GameAuditUpdate, &updated, game, &updated, actingUserID)
+}
+
+// weekPickCounts returns how many picks each game in a week has
+func weekPickCounts(q sqlx.Queryer, weekID string) (map[string]int, error) {
+	var rows []struct {
+		GameID string `db:"game_id"`
+		Picks  int    `db:"picks"`
+	}
+	err := sqlx.Select(q, &rows, `
+		SELECT game_id, COUNT(*) AS picks
+		FROM public.picks
+		WHERE week_id = $1
+		GROUP BY game_id
+	`, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.GameID] = row.Picks
+	}
+	return counts, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// season_roles roles
+const (
+	SeasonRoleOwner          = "owner"           // created the season
+	SeasonRoleCoCommissioner = "co_commissioner" // helps run the season
+	SeasonRoleParticipant    = "participant"
+	SeasonRoleSpectator      = "spectator"
+)
+
+var (
+	ErrSeasonNotFound         = errors.New("season not found")
+	ErrSeasonRoleNotFound     = errors.New("user has no role in this season")
+	ErrInvalidSeasonRole      = errors.New("unknown season role")
+	ErrSeasonRoleUserNotFound = errors.New("user not found")
+	ErrLastSeasonOwner        = errors.New("a season needs at least one owner")
+	ErrUnknownSeasonRoute     = errors.New("route has no season, week or game")
+)
+
+// IsValidSeasonRole checks the role is one a user can have in a season
+func IsValidSeasonRole(role string) bool {
+	switch role {
+	case SeasonRoleOwner, SeasonRoleCoCommissioner, SeasonRoleParticipant, SeasonRoleSpectator:
+		return true
+	}
+	return false
+}
+
+// GetSeasonRoles lists everyone with a role in a season, owners and co-commissioners first
+func GetSeasonRoles(db *sqlx.DB, seasonID string) ([]models.SeasonRole, error) {
+	roles := []models.SeasonRole{}
+	err := db.Select(&roles, `
+		SELECT sr.*, p.username
+		FROM public.season_roles sr
+		JOIN public.profiles p ON p.id = sr.user_id
+		WHERE sr.season_id = $1
+		ORDER BY
+			CASE sr.role WHEN 'owner' THEN 0 WHEN 'co_commissioner' THEN 1 WHEN 'participant' THEN 2 ELSE 3 END,
+			p.username
+	`, seasonID)
+	return roles, err
+}
+
+// GetSeasonRole returns a user's role in a season, or "" if they don't have one
+func GetSeasonRole(q sqlx.Queryer, seasonID string, userID string) (string, error) {
+	var role string
+	err := sqlx.Get(q, &role, `SELECT role FROM public.season_roles WHERE season_id = $1 AND user_id = $2`, seasonID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// AddSeasonRole gives a user a role in a season unless they already have one (an owner who is also playing
+// stays the owner).  assignedBy is nil when the role comes with creating the season or adding participants
+func AddSeasonRole(e sqlx.Execer, seasonID string, userID string, role string, assignedBy *string) error {
+	if !IsValidSeasonRole(role) {
+		return ErrInvalidSeasonRole
+	}
+	_, err := e.Exec(`
+		INSERT INTO public.season_roles (season_id, user_id, role, assigned_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (season_id, user_id) DO NOTHING
+	`, seasonID, userID, role, assignedBy)
+	return seasonRoleWriteError(err)
+}
+
+// SetSeasonRole assigns a user's role in a season, replacing any role they had.  The last owner can't be demoted
+func SetSeasonRole(db *sqlx.DB, seasonID string, userID string, role string, assignedBy string) (*models.SeasonRole, error) {
+	if !IsValidSeasonRole(role) {
+		return nil, ErrInvalidSeasonRole
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockSeasonRoles(tx, seasonID); err != nil {
+		return nil, err
+	}
+
+	current, err := GetSeasonRole(tx, seasonID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current == SeasonRoleOwner && role != SeasonRoleOwner {
+		if err := checkOtherSeasonOwner(tx, seasonID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var seasonRole models.SeasonRole
+	err = tx.Get(&seasonRole, `
+		INSERT INTO public.season_roles (season_id, user_id, role, assigned_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (season_id, user_id)
+		DO UPDATE
+		SET role = EXCLUDED.role,
+			assigned_by = EXCLUDED.assigned_by
+		RETURNING *
+	`, seasonID, userID, role, assignedBy)
+	if err != nil {
+		return nil, seasonRoleWriteError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &seasonRole, nil
+}
+
+// RemoveSeasonRole takes away a user's role in a season.  It doesn't remove them from season_participants.
+// The last owner can't be removed
+func RemoveSeasonRole(db *sqlx.DB, seasonID string, userID string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockSeasonRoles(tx, seasonID); err != nil {
+		return err
+	}
+
+	current, err := GetSeasonRole(tx, seasonID, userID)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return ErrSeasonRoleNotFound
+	}
+	if current == SeasonRoleOwner {
+		if err := checkOtherSeasonOwner(tx, seasonID, userID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM public.season_roles WHERE season_id = $1 AND user_id = $2`, seasonID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockSeasonRoles locks the season so two role changes can't both take away "the other" owner
+func lockSeasonRoles(tx *sqlx.Tx, seasonID string) error {
+	var lockedID string
+	err := tx.Get(&lockedID, `SELECT id FROM public.seasons WHERE id = $1 FOR UPDATE`, seasonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSeasonNotFound
+	}
+	return err
+}
+
+// checkOtherSeasonOwner makes sure someone besides userID owns the season
+func checkOtherSeasonOwner(tx *sqlx.Tx, seasonID string, userID string) error {
+	var owners int
+	err := tx.Get(&owners, `
+		SELECT COUNT(*)
+		FROM public.season_roles
+		WHERE season_id = $1 AND role = 'owner' AND user_id <> $2
+	`, seasonID, userID)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastSeasonOwner
+	}
+	return nil
+}
+
+// SeasonIDForRoute returns the season a route's season_id, week_id or game_id (kind is the route param) is in
+func SeasonIDForRoute(q sqlx.Queryer, kind string, resourceID string) (string, error) {
+	var query string
+	switch kind {
+	case "season_id":
+		query = `SELECT id FROM public.seasons WHERE id = $1`
+	case "week_id":
+		query = `SELECT season_id FROM public.weeks WHERE id = $1`
+	case "game_id":
+		query = `SELECT season_id FROM public.games WHERE id = $1`
+	default:
+		return "", ErrUnknownSeasonRoute
+	}
+
+	var seasonID string
+	if err := sqlx.Get(q, &seasonID, query, resourceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrSeasonNotFound
+		}
+		return "", err
+	}
+	return seasonID, nil
+}
+
+// seasonRoleWriteError turns the season_roles user foreign key violation into ErrSeasonRoleUserNotFound
+func seasonRoleWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrSeasonRoleUserNotFound
+	}
+	return err
+}
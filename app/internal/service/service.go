@@ -4,13 +4,52 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"pawked.com/sendyourpicks/internal/models"
 )
 
+// Season scoring modes
+const (
+	ScoringModeStandard   = "standard"   // every correct pick is worth points_per_correct_pick
+	ScoringModeConfidence = "confidence" // every correct pick is worth its confidence value
+)
+
+// Season push policies.  A push is a game that lands exactly on the spread
+const (
+	PushPolicyLoss = "loss" // picks on a push are incorrect
+	PushPolicyWin  = "win"  // picks on a push are correct
+	PushPolicyHalf = "half" // picks on a push are worth half their points
+	PushPolicyVoid = "void" // the game doesn't count for anyone
+)
+
+// Over/under pick values
+const (
+	TotalPickOver  = "over"
+	TotalPickUnder = "under"
+)
+
+// IsValidPushPolicy returns true if the policy is one of the known push policies
+func IsValidPushPolicy(policy string) bool {
+	switch policy {
+	case PushPolicyLoss, PushPolicyWin, PushPolicyHalf, PushPolicyVoid:
+		return true
+	}
+	return false
+}
+
+// PushPointsMultiplier returns the share of a pick's points awarded for a push that isn't graded
+// as a plain win or loss.  Only half pushes are worth anything.
+func PushPointsMultiplier(policy string) float64 {
+	if policy == PushPolicyHalf {
+		return 0.5
+	}
+	return 0
+}
+
 // get all picks for a given week
-func GetWeekPicks(db *sqlx.DB, weekID string) ([]models.Pick, error) {
+func GetWeekPicks(q sqlx.Queryer, weekID string) ([]models.Pick, error) {
 	var weekPicks []models.Pick
 
 	query := `
@@ -21,11 +60,13 @@ func GetWeekPicks(db *sqlx.DB, weekID string) ([]models.Pick, error) {
 			week_id,
 			selected_team_id,
 			is_correct,
-			calculated_at
+			calculated_at,
+			confidence,
+			total_pick
 		FROM public.picks
 		WHERE week_id = $1
 	`
-	err := db.Select(&weekPicks, query, weekID)
+	err := sqlx.Select(q, &weekPicks, query, weekID)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +75,7 @@ func GetWeekPicks(db *sqlx.DB, weekID string) ([]models.Pick, error) {
 }
 
 // GetWeekGames returns all games for a given week
-func GetWeekGames(db *sqlx.DB, weekID string) ([]models.Game, error) {
+func GetWeekGames(q sqlx.Queryer, weekID string) ([]models.Game, error) {
 	var weekGames []models.Game
 
 	query := `
@@ -47,12 +88,15 @@ func GetWeekGames(db *sqlx.DB, weekID string) ([]models.Game, error) {
 			home_score,
 			away_score,
 			home_spread,
-			status
+			total,
+			status,
+			kickoff_time,
+			voided_at
 		FROM public.games
 		WHERE week_id = $1
 	`
 
-	if err := db.Select(&weekGames, query, weekID); err != nil {
+	if err := sqlx.Select(q, &weekGames, query, weekID); err != nil {
 		return nil, err
 	}
 
@@ -104,17 +148,22 @@ func SeasonExists(db *sqlx.DB, seasonID string) (bool, error) {
 }
 
 // returns a week with the year from the database if it exists
-func GetWeekWithYear(db *sqlx.DB, weekID string) (*models.WeekWithYear, error) {
+func GetWeekWithYear(q sqlx.Queryer, weekID string) (*models.WeekWithYear, error) {
 	var week models.WeekWithYear
 
-	err := db.Get(&week, `
+	err := sqlx.Get(q, &week, `
 		SELECT
 			w.id,
 			w.season_id,
 			w.number,
 			w.status,
+			w.totals_enabled,
 			s.year,
-			s.is_postseason
+			s.is_postseason,
+			s.scoring_mode,
+			s.season_type,
+			s.survivor_use_spread,
+			s.push_policy
 		FROM weeks w
 		JOIN seasons s ON s.id = w.season_id
 		WHERE w.id = $1
@@ -149,13 +198,22 @@ func GetSeasonIDbyYear(db *sqlx.DB, year int) (string, error) {
 	return seasonID, nil
 }
 
-// GetTeamIDByAbbreviation looks up an active team's ID by its abbreviation
-func GetTeamIDByAbbreviation(db *sqlx.DB, abbreviation string) (string, error) {
+// GetTeamIDByAbbreviation looks up an active team's ID by its abbreviation, or by one of the provider's
+// team aliases (see team_aliases) when the provider uses a different abbreviation
+func GetTeamIDByAbbreviation(q sqlx.Queryer, provider string, abbreviation string) (string, error) {
 	var teamID string
-	err := db.Get(&teamID,
-		`SELECT id FROM public.teams WHERE abbreviation = $1 AND is_active = true`,
-		abbreviation,
-	)
+	err := sqlx.Get(q, &teamID, `
+		SELECT t.id
+		FROM public.teams t
+		LEFT JOIN public.team_aliases ta
+			ON ta.team_id = t.id
+			AND ta.provider = ANY($2)
+			AND lower(ta.alias) = lower($1)
+		WHERE t.is_active = true
+		  AND (t.abbreviation = $1 OR ta.id IS NOT NULL)
+		ORDER BY (t.abbreviation = $1) DESC
+		LIMIT 1
+	`, abbreviation, append(strings.Split(provider, "+"), TeamAliasProviderAny))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", fmt.Errorf("team mapping failed for abbr %s: %w", abbreviation, ErrTeamMappingFailed)
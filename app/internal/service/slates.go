@@ -0,0 +1,186 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"pawked.com/sendyourpicks/internal/models"
+	"pawked.com/sendyourpicks/internal/settings"
+)
+
+// Sunday slate keys.  Other days use the lowercase weekday (thursday, saturday, monday, ...)
+const (
+	SlateSundayMorning = "sunday_morning"
+	SlateSundayEarly   = "sunday_early"
+	SlateSundayLate    = "sunday_late"
+	SlateSundayNight   = "sunday_night"
+)
+
+// Sunday kickoffs within this long of a window's first kickoff are in the same window (1:00 and 1:05, 4:05 and 4:25)
+const slateWindow = 90 * time.Minute
+
+var slateLabels = map[string]string{
+	SlateSundayMorning: "Sunday Morning",
+	SlateSundayEarly:   "Sunday Early",
+	SlateSundayLate:    "Sunday Late",
+	SlateSundayNight:   "Sunday Night",
+}
+
+// Slate is a group of a week's games that kick off together, worked out in the competition timezone
+type Slate struct {
+	Key      string     `json:"key"`
+	Label    string     `json:"label"`
+	Date     string     `json:"date"` // local date of the slate, 2006-01-02
+	StartsAt time.Time  `json:"starts_at"`
+	LocksAt  *time.Time `json:"locks_at"` // the slate's first pick deadline, nil when picks don't lock (testing mode)
+	GameIDs  []string   `json:"game_ids"`
+}
+
+// PickDeadlines returns when picks lock for each game (game id -> deadline), from the kickoffs (game id -> kickoff).
+// Games lock pick_cutoff_minutes before kickoff, and with the sunday_1pm rule no later than 1:00 PM on the week's
+// Sunday in the competition timezone.  A cutoff of -1 (testing mode) means nothing locks and the map is empty
+func PickDeadlines(kickoffs map[string]time.Time, s *settings.Settings) map[string]time.Time {
+	deadlines := make(map[string]time.Time, len(kickoffs))
+	if s.PickCutoffMinutes == -1 || len(kickoffs) == 0 {
+		return deadlines
+	}
+
+	var sundayLock time.Time
+	if s.PickLockRule == settings.PickLockSunday1PM {
+		sundayLock = weekSundayLock(kickoffs, s.Location())
+	}
+
+	cutoff := time.Duration(s.PickCutoffMinutes) * time.Minute
+	for gameID, kickoff := range kickoffs {
+		deadline := kickoff.Add(-cutoff)
+		if !sundayLock.IsZero() && sundayLock.Before(deadline) {
+			deadline = sundayLock
+		}
+		deadlines[gameID] = deadline.UTC()
+	}
+	return deadlines
+}
+
+// weekSundayLock is 1:00 PM local on the first Sunday on or after the week's first kickoff
+func weekSundayLock(kickoffs map[string]time.Time, loc *time.Location) time.Time {
+	var first time.Time
+	for _, kickoff := range kickoffs {
+		if first.IsZero() || kickoff.Before(first) {
+			first = kickoff
+		}
+	}
+
+	local := first.In(loc)
+	daysToSunday := (7 - int(local.Weekday())) % 7
+	return time.Date(local.Year(), local.Month(), local.Day()+daysToSunday, 13, 0, 0, 0, loc)
+}
+
+// WeekSlates groups a week's games into slates in the competition timezone.  Non-Sunday games are grouped by day.
+// Sunday games are split into kickoff windows: the window with the most games is Sunday Early, windows before it
+// are Sunday Morning (international games) and windows after it Sunday Late, except a last single game in the
+// evening which is Sunday Night.  Working from the windows instead of fixed hours keeps the names right in any
+// timezone
+func WeekSlates(games []models.Game, s *settings.Settings) []Slate {
+	if len(games) == 0 {
+		return []Slate{}
+	}
+	loc := s.Location()
+
+	sorted := make([]models.Game, len(games))
+	copy(sorted, games)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].KickoffTime.Before(sorted[j].KickoffTime)
+	})
+
+	kickoffs := make(map[string]time.Time, len(sorted))
+	for _, game := range sorted {
+		kickoffs[game.ID] = game.KickoffTime
+	}
+	deadlines := PickDeadlines(kickoffs, s)
+
+	keys := slateKeys(sorted, loc)
+
+	slates := []Slate{}
+	for i, game := range sorted {
+		date := game.KickoffTime.In(loc).Format(time.DateOnly)
+
+		last := len(slates) - 1
+		if last < 0 || slates[last].Key != keys[i] || slates[last].Date != date {
+			slates = append(slates, Slate{
+				Key:      keys[i],
+				Label:    slateLabel(keys[i]),
+				Date:     date,
+				StartsAt: game.KickoffTime,
+				GameIDs:  []string{},
+			})
+			last++
+		}
+
+		slate := &slates[last]
+		slate.GameIDs = append(slate.GameIDs, game.ID)
+		if deadline, ok := deadlines[game.ID]; ok && (slate.LocksAt == nil || deadline.Before(*slate.LocksAt)) {
+			slate.LocksAt = &deadline
+		}
+	}
+	return slates
+}
+
+// slateKeys returns the slate key for each game (sorted by kickoff)
+func slateKeys(games []models.Game, loc *time.Location) []string {
+	keys := make([]string, len(games))
+
+	// Sunday windows, as index ranges into games
+	type window struct{ start, end int }
+	var windows []window
+
+	for i, game := range games {
+		local := game.KickoffTime.In(loc)
+		if local.Weekday() != time.Sunday {
+			keys[i] = strings.ToLower(local.Weekday().String())
+			continue
+		}
+
+		if n := len(windows); n > 0 && game.KickoffTime.Sub(games[windows[n-1].start].KickoffTime) <= slateWindow {
+			windows[n-1].end = i + 1
+		} else {
+			windows = append(windows, window{start: i, end: i + 1})
+		}
+	}
+	if len(windows) == 0 {
+		return keys
+	}
+
+	// a lone game in the evening is the Sunday night game
+	if last := windows[len(windows)-1]; last.end-last.start == 1 && games[last.start].KickoffTime.In(loc).Hour() >= 17 {
+		keys[last.start] = SlateSundayNight
+		windows = windows[:len(windows)-1]
+	}
+
+	main := 0
+	for i, w := range windows {
+		if w.end-w.start > windows[main].end-windows[main].start {
+			main = i
+		}
+	}
+
+	for i, w := range windows {
+		key := SlateSundayEarly
+		if i < main {
+			key = SlateSundayMorning
+		} else if i > main {
+			key = SlateSundayLate
+		}
+		for g := w.start; g < w.end; g++ {
+			keys[g] = key
+		}
+	}
+	return keys
+}
+
+func slateLabel(key string) string {
+	if label, ok := slateLabels[key]; ok {
+		return label
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/id"
+)
+
+// how far a line has to move after activation before the commissioner view flags it
+const DefaultLineMovementThreshold = 1.5
+
+// the Odds API drops games once they're played, so lines are only recorded in these statuses
+var ErrWeekLinesNotRecordable = errors.New("lines can only be recorded while the week is in games_imported, spreads_set or active status")
+
+// SpreadHistoryEntry is one bookmaker's line for a game at one point in time
+type SpreadHistoryEntry struct {
+	Bookmaker      string    `json:"bookmaker" db:"bookmaker"`
+	BookmakerTitle string    `json:"bookmaker_title" db:"bookmaker_title"`
+	HomeSpread     float64   `json:"home_spread" db:"home_spread"`
+	Total          *float64  `json:"total" db:"total"`
+	LastUpdate     time.Time `json:"last_update" db:"last_update"`
+	FetchedAt      time.Time `json:"fetched_at" db:"fetched_at"`
+}
+
+type GameSpreadHistory struct {
+	GameID       string               `json:"game_id"`
+	HomeTeamAbbr string               `json:"home_team_abbr"`
+	AwayTeamAbbr string               `json:"away_team_abbr"`
+	HomeSpread   *float64             `json:"home_spread"` // the line picks are graded against
+	History      []SpreadHistoryEntry `json:"history"`
+}
+
+// GameLineMovement compares a game's line now to the line the week was activated with
+type GameLineMovement struct {
+	GameID        string     `json:"game_id"`
+	Matchup       string     `json:"matchup"`
+	KickoffTime   time.Time  `json:"kickoff_time"`
+	HomeSpread    *float64   `json:"home_spread"`    // line at activation
+	CurrentSpread *float64   `json:"current_spread"` // median of each bookmaker's latest line
+	BookCount     int        `json:"book_count"`
+	Movement      *float64   `json:"movement"` // current - home_spread
+	LastUpdate    *time.Time `json:"last_update"`
+	Flagged       bool       `json:"flagged"`
+}
+
+// SpreadHistoryRecordResult is what RecordWeekSpreadHistory fetched and stored
+type SpreadHistoryRecordResult struct {
+	WeekID        string `json:"week_id"`
+	Status        string `json:"status"`
+	GamesMatched  int    `json:"games_matched"`
+	GamesTotal    int    `json:"games_total"`
+	LinesRecorded int    `json:"lines_recorded"`
+}
+
+type WeekLineMovement struct {
+	WeekID      string             `json:"week_id"`
+	Status      string             `json:"status"`
+	ActivatedAt *time.Time         `json:"activated_at"`
+	Threshold   float64            `json:"threshold"`
+	Flagged     int                `json:"flagged"`
+	Games       []GameLineMovement `json:"games"`
+}
+
+// RecordSpreadHistory stores every bookmaker line for a game.  Lines the bookmaker hasn't updated since the
+// last fetch are already there and get skipped.  Returns how many new lines were stored
+func RecordSpreadHistory(e sqlx.Execer, gameID string, lines []external.BookmakerLine) (int, error) {
+	recorded := 0
+	for _, line := range lines {
+		historyID, err := id.New()
+		if err != nil {
+			return recorded, err
+		}
+
+		result, err := e.Exec(`
+			INSERT INTO public.spread_history
+			(id, game_id, bookmaker, bookmaker_title, home_spread, total, last_update)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (game_id, bookmaker, last_update) DO NOTHING
+		`, historyID, gameID, line.Key, line.Title, line.HomeSpread, line.Total, line.LastUpdate)
+		if err != nil {
+			return recorded, err
+		}
+
+		rows, _ := result.RowsAffected()
+		recorded += int(rows)
+	}
+	return recorded, nil
+}
+
+// RecordWeekSpreadHistory fetches every bookmaker's current line for a week's games and only adds them to the
+// spread history.  Spreads aren't changed, so this works after the week is active to track line movement.
+// Returns ErrWeekLinesNotRecordable (with the result's Status set) for weeks the Odds API has no lines for
+func RecordWeekSpreadHistory(ctx context.Context, db *sqlx.DB, weekID string) (*SpreadHistoryRecordResult, error) {
+	week, err := GetWeekWithYear(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SpreadHistoryRecordResult{WeekID: weekID, Status: week.Status}
+	if week.Status != StatusGamesImported && week.Status != StatusSpreadsSet && week.Status != StatusActive {
+		return result, ErrWeekLinesNotRecordable
+	}
+
+	games, err := GetSpreadImportGames(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+	result.GamesTotal = len(games)
+
+	oddsClient, err := external.NewOddsClient()
+	if err != nil {
+		return nil, err
+	}
+	teamAliases, err := GetTeamAliasMap(db, external.ProviderOdds)
+	if err != nil {
+		return nil, err
+	}
+	oddsClient = oddsClient.ForWeek(week.Year, week.Number, week.IsPostseason).WithTeamAliases(teamAliases)
+
+	// the method doesn't matter here, only the per bookmaker lines are kept
+	spreads, err := oddsClient.FetchConsensusSpreads(ctx, external.SpreadMethodMedian, week.TotalsEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, game := range games {
+		matchedSpread := MatchGameSpread(game, spreads)
+		if matchedSpread == nil {
+			continue
+		}
+		result.GamesMatched++
+
+		recorded, err := RecordSpreadHistory(tx, game.ID, matchedSpread.Lines)
+		if err != nil {
+			return nil, err
+		}
+		result.LinesRecorded += recorded
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetGameSpreadHistory returns every recorded line for a game, oldest first
+func GetGameSpreadHistory(db *sqlx.DB, gameID string) (*GameSpreadHistory, error) {
+	var game struct {
+		HomeTeamAbbr string   `db:"home_team_abbr"`
+		AwayTeamAbbr string   `db:"away_team_abbr"`
+		HomeSpread   *float64 `db:"home_spread"`
+	}
+	err := db.Get(&game, `
+		SELECT home_team_abbr, away_team_abbr, home_spread
+		FROM public.games
+		WHERE id = $1
+	`, gameID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, err
+	}
+
+	history := []SpreadHistoryEntry{}
+	err = db.Select(&history, `
+		SELECT bookmaker, bookmaker_title, home_spread, total, last_update, fetched_at
+		FROM public.spread_history
+		WHERE game_id = $1
+		ORDER BY last_update, bookmaker
+	`, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GameSpreadHistory{
+		GameID:       gameID,
+		HomeTeamAbbr: game.HomeTeamAbbr,
+		AwayTeamAbbr: game.AwayTeamAbbr,
+		HomeSpread:   game.HomeSpread,
+		History:      history,
+	}, nil
+}
+
+// GetWeekLineMovement compares each game's current line with the spread it was activated with and flags
+// the ones that moved more than threshold points.  The current line is the median of each bookmaker's
+// latest recorded line.  A book that hasn't moved since activation still counts at its activation line
+func GetWeekLineMovement(db *sqlx.DB, weekID string, threshold float64) (*WeekLineMovement, error) {
+	var week struct {
+		Status      string     `db:"status"`
+		ActivatedAt *time.Time `db:"activated_at"`
+	}
+	err := db.Get(&week, `SELECT status, activated_at FROM public.weeks WHERE id = $1`, weekID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWeekNotFound
+		}
+		return nil, err
+	}
+
+	var rows []struct {
+		GameID        string     `db:"game_id"`
+		HomeTeamAbbr  string     `db:"home_team_abbr"`
+		AwayTeamAbbr  string     `db:"away_team_abbr"`
+		KickoffTime   time.Time  `db:"kickoff_time"`
+		HomeSpread    *float64   `db:"home_spread"`
+		CurrentSpread *float64   `db:"current_spread"`
+		BookCount     int        `db:"book_count"`
+		LastUpdate    *time.Time `db:"last_update"`
+	}
+	err = db.Select(&rows, `
+		WITH latest AS (
+			SELECT DISTINCT ON (sh.game_id, sh.bookmaker)
+				sh.game_id,
+				sh.home_spread,
+				sh.last_update
+			FROM public.spread_history sh
+			JOIN public.games g ON g.id = sh.game_id
+			WHERE g.week_id = $1
+			ORDER BY sh.game_id, sh.bookmaker, sh.last_update DESC
+		)
+		SELECT
+			g.id AS game_id,
+			g.home_team_abbr,
+			g.away_team_abbr,
+			g.kickoff_time,
+			g.home_spread,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY l.home_spread) AS current_spread,
+			COUNT(l.game_id) AS book_count,
+			MAX(l.last_update) AS last_update
+		FROM public.games g
+		LEFT JOIN latest l ON l.game_id = g.id
+		WHERE g.week_id = $1
+		  AND g.voided_at IS NULL
+		GROUP BY g.id
+		ORDER BY g.kickoff_time, g.id
+	`, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WeekLineMovement{
+		WeekID:      weekID,
+		Status:      week.Status,
+		ActivatedAt: week.ActivatedAt,
+		Threshold:   threshold,
+		Games:       make([]GameLineMovement, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		movement := GameLineMovement{
+			GameID:        row.GameID,
+			Matchup:       row.AwayTeamAbbr + " @ " + row.HomeTeamAbbr,
+			KickoffTime:   row.KickoffTime,
+			HomeSpread:    row.HomeSpread,
+			CurrentSpread: row.CurrentSpread,
+			BookCount:     row.BookCount,
+			LastUpdate:    row.LastUpdate,
+		}
+
+		if row.HomeSpread != nil && row.CurrentSpread != nil {
+			moved := *row.CurrentSpread - *row.HomeSpread
+			movement.Movement = &moved
+			movement.Flagged = math.Abs(moved) > threshold
+		}
+		if movement.Flagged {
+			result.Flagged++
+		}
+
+		result.Games = append(result.Games, movement)
+	}
+
+	return result, nil
+}
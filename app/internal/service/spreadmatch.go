@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// kickoff tolerance when matching Odds API games to ours.
+// 6 hours handles timezone differences and slight schedule adjustments
+const kickoffTolerance = 6 * time.Hour
+
+// timesAreClose checks if two times are within the tolerance window
+// This handles timezone differences and slight schedule adjustments
+func timesAreClose(t1, t2 time.Time, tolerance time.Duration) bool {
+	diff := math.Abs(float64(t1.Sub(t2)))
+	return diff <= float64(tolerance)
+}
+
+// GetSpreadImportGames gets all games for a week with the team abbreviations needed to match Odds API games
+func GetSpreadImportGames(q sqlx.Queryer, weekID string) ([]models.Game, error) {
+	var games []models.Game
+	err := sqlx.Select(q, &games, `
+		SELECT
+			g.id,
+			g.home_team_id,
+			g.away_team_id,
+			g.home_spread,
+			g.kickoff_time,
+			ht.abbreviation as home_team_abbr,
+			at.abbreviation as away_team_abbr,
+			ht.name as home_team_name,
+			at.name as away_team_name
+		FROM games g
+		JOIN teams ht ON g.home_team_id = ht.id
+		JOIN teams at ON g.away_team_id = at.id
+		WHERE g.week_id = $1
+	`, weekID)
+	return games, err
+}
+
+// MatchGameSpread finds the Odds API line for a game using team matchup AND kickoff time
+func MatchGameSpread(game models.Game, spreads []external.SpreadInfo) *external.SpreadInfo {
+	// Look for matching spread by team matchup AND kickoff time
+	for i := range spreads {
+		spread := &spreads[i]
+
+		// Check if teams match
+		teamsMatch := (spread.HomeTeamAbbr == game.HomeTeamAbbr &&
+			spread.AwayTeamAbbr == game.AwayTeamAbbr)
+
+		if !teamsMatch {
+			continue
+		}
+
+		// Check if kickoff times are close
+		if timesAreClose(spread.CommenceTime, game.KickoffTime, kickoffTolerance) {
+			logger.Info(
+				"matched game with spread",
+				"game_id", game.ID,
+				"matchup", fmt.Sprintf("%s @ %s", game.AwayTeamAbbr, game.HomeTeamAbbr),
+				"spread", spread.HomeSpread,
+				"db_kickoff", game.KickoffTime.Format(time.RFC3339),
+				"odds_kickoff", spread.CommenceTime.Format(time.RFC3339),
+			)
+			return spread
+		} else {
+			logger.Warn(
+				"teams match but kickoff times too different",
+				"game_id", game.ID,
+				"matchup", fmt.Sprintf("%s @ %s", game.AwayTeamAbbr, game.HomeTeamAbbr),
+				"db_kickoff", game.KickoffTime.Format(time.RFC3339),
+				"odds_kickoff", spread.CommenceTime.Format(time.RFC3339),
+				"time_diff_hours", math.Abs(float64(spread.CommenceTime.Sub(game.KickoffTime).Hours())),
+			)
+		}
+	}
+	return nil
+}
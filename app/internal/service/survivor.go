@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/logger"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// Season types
+const (
+	SeasonTypePickem   = "pickem"   // pick every game, points per correct pick
+	SeasonTypeSurvivor = "survivor" // one team per week, eliminated on the first loss
+)
+
+// WinningTeamStraightUp returns the winning team ID based on score alone, and nil for ties or a game without scores.
+// Used by survivor seasons that don't grade against the spread.
+func WinningTeamStraightUp(game models.Game) *string {
+	if game.HomeScore == nil || game.AwayScore == nil {
+		return nil
+	}
+
+	if *game.HomeScore > *game.AwayScore {
+		return &game.HomeTeamID
+	}
+	if *game.HomeScore < *game.AwayScore {
+		return &game.AwayTeamID
+	}
+	return nil
+}
+
+// IsUserEliminated returns true if the user has been eliminated from a survivor season
+func IsUserEliminated(db *sqlx.DB, seasonID string, userID string) (bool, error) {
+	var eliminated bool
+	err := db.Get(&eliminated, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.season_participants
+			WHERE season_id = $1 AND user_id = $2 AND eliminated_at IS NOT NULL
+		)
+	`, seasonID, userID)
+	if err != nil {
+		return false, err
+	}
+	return eliminated, nil
+}
+
+// IsSurvivorTeamUsed returns true if the user already picked the team in a different week of the season
+func IsSurvivorTeamUsed(tx *sqlx.Tx, seasonID string, weekID string, userID string, teamID string) (bool, error) {
+	var used bool
+	err := tx.Get(&used, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM public.picks pk
+			JOIN public.weeks w ON w.id = pk.week_id
+			WHERE w.season_id = $1
+			AND pk.week_id <> $2
+			AND pk.user_id = $3
+			AND pk.selected_team_id = $4
+		)
+	`, seasonID, weekID, userID, teamID)
+	if err != nil {
+		return false, err
+	}
+	return used, nil
+}
+
+type SurvivorEliminationResult struct {
+	UsersEliminated int
+}
+
+// ApplySurvivorEliminations eliminates every survivor still alive whose pick for the week lost,
+// or who didn't make a pick at all.  Pushes follow the season's push policy: a push graded as a loss
+// eliminates, anything else (win, half, void) survives.
+// Safe to run more than once for the same week since already eliminated users are skipped.
+// Does nothing for pick'em seasons.
+func ApplySurvivorEliminations(ctx context.Context, db *sqlx.DB, weekID string) (*SurvivorEliminationResult, error) {
+	week, err := GetWeekWithYear(db, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	if week.SeasonType != SeasonTypeSurvivor {
+		return &SurvivorEliminationResult{}, nil
+	}
+
+	if week.Status != StatusPicksResultsCalculated {
+		return nil, ErrWeekNotPicksCalculated
+	}
+
+	logger.Debug("ApplySurvivorEliminations: starting for week", "week_id", weekID, "season_id", week.SeasonID)
+
+	result, err := applySurvivorEliminations(db, week)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("ApplySurvivorEliminations: completed", "users_eliminated", result.UsersEliminated)
+
+	return result, nil
+}
+
+// applySurvivorEliminations does the eliminating for ApplySurvivorEliminations without any status checks,
+// so score corrections can replay eliminations for weeks that are already scored
+func applySurvivorEliminations(e sqlx.Execer, week *models.WeekWithYear) (*SurvivorEliminationResult, error) {
+	result, err := e.Exec(`
+		UPDATE public.season_participants sp
+		SET eliminated_week_id = $1,
+			eliminated_at = $3
+		WHERE sp.season_id = $2
+		AND sp.eliminated_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM public.picks pk
+			WHERE pk.week_id = $1
+			AND pk.user_id = sp.user_id
+			AND pk.selected_team_id IS NOT NULL
+			AND pk.is_correct IS DISTINCT FROM false
+		)
+	`, week.ID, week.SeasonID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := result.RowsAffected()
+
+	return &SurvivorEliminationResult{
+		UsersEliminated: int(rows),
+	}, nil
+}
+
+// SurvivorStanding is a single participant's survivor status
+type SurvivorStanding struct {
+	UserID               string   `json:"user_id" db:"user_id"`
+	Username             string   `json:"username" db:"username"`
+	AvatarURL            *string  `json:"avatar_url" db:"avatar_url"`
+	IsAlive              bool     `json:"is_alive" db:"is_alive"`
+	EliminatedWeekNumber *int     `json:"eliminated_week_number" db:"eliminated_week_number"`
+	WeeksSurvived        int      `json:"weeks_survived" db:"weeks_survived"`
+	TeamsUsed            []string `json:"teams_used" db:"-"`
+}
+
+// GetSurvivorStandings returns every participant in a survivor season with whether they're still
+// alive, when they were knocked out, and which teams they've already used.
+// Alive users come first, then users sorted by how long they lasted.
+func GetSurvivorStandings(db *sqlx.DB, seasonID string) ([]SurvivorStanding, error) {
+	var standings []SurvivorStanding
+	err := db.Select(&standings, `
+		SELECT
+			sp.user_id,
+			COALESCE(p.username, '') AS username,
+			p.avatar_url,
+			sp.eliminated_at IS NULL AS is_alive,
+			ew.number AS eliminated_week_number,
+			(
+				SELECT COUNT(*)
+				FROM public.weeks w
+				WHERE w.season_id = sp.season_id
+				AND w.status = 'final'
+				AND (ew.number IS NULL OR w.number < ew.number)
+			) AS weeks_survived
+		FROM public.season_participants sp
+		LEFT JOIN public.profiles p ON p.id = sp.user_id
+		LEFT JOIN public.weeks ew ON ew.id = sp.eliminated_week_id
+		WHERE sp.season_id = $1
+		ORDER BY is_alive DESC, weeks_survived DESC, username ASC
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	// teams each user has picked so far, in week order
+	type usedTeam struct {
+		UserID       string `db:"user_id"`
+		Abbreviation string `db:"abbreviation"`
+	}
+
+	var used []usedTeam
+	err = db.Select(&used, `
+		SELECT pk.user_id, t.abbreviation
+		FROM public.picks pk
+		JOIN public.weeks w ON w.id = pk.week_id
+		JOIN public.teams t ON t.id = pk.selected_team_id
+		WHERE w.season_id = $1
+		ORDER BY w.number ASC
+	`, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamsByUserID := make(map[string][]string)
+	for _, u := range used {
+		teamsByUserID[u.UserID] = append(teamsByUserID[u.UserID], u.Abbreviation)
+	}
+
+	for i := range standings {
+		standings[i].TeamsUsed = teamsByUserID[standings[i].UserID]
+		if standings[i].TeamsUsed == nil {
+			standings[i].TeamsUsed = []string{}
+		}
+	}
+
+	if standings == nil {
+		standings = []SurvivorStanding{}
+	}
+
+	return standings, nil
+}
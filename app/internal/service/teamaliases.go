@@ -0,0 +1,194 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/external"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/models"
+)
+
+// aliases with this provider apply to every provider
+const TeamAliasProviderAny = "any"
+
+// postgres unique_violation
+const pgUniqueViolation = "23505"
+
+var (
+	ErrTeamAliasNotFound    = errors.New("team alias not found")
+	ErrTeamAliasExists      = errors.New("provider already has this alias")
+	ErrTeamAliasRequired    = errors.New("alias is required")
+	ErrInvalidAliasProvider = errors.New("unknown alias provider")
+	ErrTeamNotFound         = errors.New("team not found")
+)
+
+var teamAliasProviders = []string{TeamAliasProviderAny, external.ProviderOdds, external.ProviderBallDontLie, external.ProviderESPN}
+
+// IsValidAliasProvider checks the provider is one we have aliases for
+func IsValidAliasProvider(provider string) bool {
+	for _, p := range teamAliasProviders {
+		if provider == p {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTeamAliases lists aliases with their team abbreviation.  An empty provider lists every alias
+func GetTeamAliases(db *sqlx.DB, provider string) ([]models.TeamAlias, error) {
+	aliases := []models.TeamAlias{}
+	err := db.Select(&aliases, `
+		SELECT ta.*, t.abbreviation AS team_abbreviation
+		FROM public.team_aliases ta
+		JOIN public.teams t ON t.id = ta.team_id
+		WHERE $1 = '' OR ta.provider = $1
+		ORDER BY ta.provider, ta.alias
+	`, provider)
+	return aliases, err
+}
+
+// CreateTeamAlias adds an alias for a team
+func CreateTeamAlias(db *sqlx.DB, provider string, alias string, teamID string) (*models.TeamAlias, error) {
+	provider, alias, err := validateTeamAlias(db, provider, alias, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	var created models.TeamAlias
+	err = db.Get(&created, `
+		INSERT INTO public.team_aliases (id, provider, alias, team_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`, aliasID, provider, alias, teamID)
+	if err != nil {
+		return nil, teamAliasWriteError(err)
+	}
+	return &created, nil
+}
+
+// UpdateTeamAlias changes an alias's provider, name or team
+func UpdateTeamAlias(db *sqlx.DB, aliasID string, provider string, alias string, teamID string) (*models.TeamAlias, error) {
+	provider, alias, err := validateTeamAlias(db, provider, alias, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated models.TeamAlias
+	err = db.Get(&updated, `
+		UPDATE public.team_aliases
+		SET provider = $2, alias = $3, team_id = $4
+		WHERE id = $1
+		RETURNING *
+	`, aliasID, provider, alias, teamID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTeamAliasNotFound
+		}
+		return nil, teamAliasWriteError(err)
+	}
+	return &updated, nil
+}
+
+// DeleteTeamAlias removes an alias
+func DeleteTeamAlias(db *sqlx.DB, aliasID string) error {
+	result, err := db.Exec(`DELETE FROM public.team_aliases WHERE id = $1`, aliasID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTeamAliasNotFound
+	}
+	return nil
+}
+
+// validateTeamAlias checks a new or updated alias and returns the cleaned up provider and alias
+func validateTeamAlias(db *sqlx.DB, provider string, alias string, teamID string) (string, string, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	alias = strings.TrimSpace(alias)
+
+	if !IsValidAliasProvider(provider) {
+		return "", "", ErrInvalidAliasProvider
+	}
+	if alias == "" {
+		return "", "", ErrTeamAliasRequired
+	}
+
+	var exists bool
+	if err := db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM public.teams WHERE id = $1)`, teamID); err != nil {
+		return "", "", err
+	}
+	if !exists {
+		return "", "", ErrTeamNotFound
+	}
+	return provider, alias, nil
+}
+
+// teamAliasWriteError turns the (provider, alias) unique index violation into ErrTeamAliasExists
+func teamAliasWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrTeamAliasExists
+	}
+	return err
+}
+
+// GetTeamAliasMap returns a provider's aliases as lowercased alias -> team abbreviation.  'any' aliases are
+// included, with the provider's own aliases taking priority.  A combined provider name like
+// "balldontlie+espn" (a games provider with a fallback) gets the aliases for each of them
+func GetTeamAliasMap(q sqlx.Queryer, provider string) (map[string]string, error) {
+	providers := append(strings.Split(provider, "+"), TeamAliasProviderAny)
+
+	var rows []struct {
+		Alias        string `db:"alias"`
+		Abbreviation string `db:"abbreviation"`
+	}
+	// 'any' sorts first so the provider specific alias wins when both exist
+	err := sqlx.Select(q, &rows, `
+		SELECT ta.alias, t.abbreviation
+		FROM public.team_aliases ta
+		JOIN public.teams t ON t.id = ta.team_id
+		WHERE ta.provider = ANY($1)
+		  AND t.is_active = true
+		ORDER BY (ta.provider <> $2), ta.alias
+	`, providers, TeamAliasProviderAny)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]string, len(rows))
+	for _, row := range rows {
+		aliases[strings.ToLower(row.Alias)] = row.Abbreviation
+	}
+	return aliases, nil
+}
+
+// resolveTeamAbbreviations rewrites provider games to use our team abbreviations wherever an alias matches
+func resolveTeamAbbreviations(q sqlx.Queryer, provider string, games []external.ProviderGame) error {
+	aliases, err := GetTeamAliasMap(q, provider)
+	if err != nil {
+		return err
+	}
+
+	for i := range games {
+		if abbr, ok := aliases[strings.ToLower(games[i].HomeTeamAbbr)]; ok {
+			games[i].HomeTeamAbbr = abbr
+		}
+		if abbr, ok := aliases[strings.ToLower(games[i].AwayTeamAbbr)]; ok {
+			games[i].AwayTeamAbbr = abbr
+		}
+	}
+	return nil
+}
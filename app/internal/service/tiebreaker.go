@@ -0,0 +1,58 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+)
+
+var (
+	ErrNoTiebreakerGame = errors.New("week has no games for a tiebreaker")
+)
+
+// TiebreakerGame is the game whose combined score users predict to break ties
+type TiebreakerGame struct {
+	ID          string    `json:"id" db:"id"`
+	KickoffTime time.Time `json:"kickoff_time" db:"kickoff_time"`
+}
+
+// GetTiebreakerGame returns the last game of the week by kickoff time (usually Monday night).
+// Works with either a *sqlx.DB or *sqlx.Tx
+func GetTiebreakerGame(q sqlx.Queryer, weekID string) (*TiebreakerGame, error) {
+	var game TiebreakerGame
+	err := sqlx.Get(q, &game, `
+		SELECT id, kickoff_time
+		FROM public.games
+		WHERE week_id = $1
+		ORDER BY kickoff_time DESC, id DESC
+		LIMIT 1
+	`, weekID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoTiebreakerGame
+		}
+		return nil, err
+	}
+	return &game, nil
+}
+
+// SaveTiebreaker stores (or replaces) a user's tiebreaker prediction for a week
+func SaveTiebreaker(tx *sqlx.Tx, userID string, weekID string, gameID string, predictedTotal int) error {
+	tiebreakerID, err := id.New()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO public.week_tiebreakers (id, user_id, week_id, game_id, predicted_total)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, week_id)
+		DO UPDATE SET
+			game_id = EXCLUDED.game_id,
+			predicted_total = EXCLUDED.predicted_total
+	`, tiebreakerID, userID, weekID, gameID, predictedTotal)
+	return err
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"pawked.com/sendyourpicks/internal/id"
+	"pawked.com/sendyourpicks/internal/logger"
+)
+
+var (
+	ErrRevertReasonRequired = errors.New("a reason is required to revert a week")
+	ErrInvalidRevertTarget  = errors.New("week can only be reverted to an earlier status")
+	ErrWeekNotLatest        = errors.New("only the latest week in a season can be reverted")
+)
+
+type RevertWeekResult struct {
+	WeekID            string
+	FromStatus        string
+	ToStatus          string
+	StandingsDeleted  int
+	ResultsDeleted    int
+	UsersUneliminated int
+	PicksUngraded     int
+	ActivationCleared bool
+}
+
+// RevertWeekState moves a week back to an earlier status and undoes the side effects of every status it leaves,
+// newest first:
+//   - leaving final clears the week's season_standings and closed_at
+//   - leaving scored clears week_results
+//   - leaving scored or picks_results_calculated clears any survivor eliminations from this week
+//   - leaving picks_results_calculated clears the pick grades
+//   - leaving active clears activated_at
+//
+// Picks, games, scores and spreads are left alone so the commissioner can fix what was wrong and advance again.
+// Only the latest week of a season can be reverted since later standings build on it.  Draft can't be a target
+// because going back to draft would mean deleting the imported games (and everyone's picks).
+func RevertWeekState(ctx context.Context, db *sqlx.DB, weekID string, toStatus string, reason string, actingUserID string) (*RevertWeekResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRevertReasonRequired
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// lock the week row so the scheduler can't advance it while we're pulling it back
+	if _, err := tx.Exec(`SELECT 1 FROM public.weeks WHERE id = $1 FOR UPDATE`, weekID); err != nil {
+		return nil, err
+	}
+
+	week, err := GetWeekWithYear(tx, weekID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := weekStatusOrder[toStatus]; !ok || toStatus == StatusDraft || isWeekPast(toStatus, week.Status) {
+		return nil, ErrInvalidRevertTarget
+	}
+
+	var isLatest bool
+	err = tx.Get(&isLatest, `
+		SELECT NOT EXISTS (
+			SELECT 1
+			FROM public.weeks
+			WHERE season_id = $1
+			AND number > $2
+		)
+	`, week.SeasonID, week.Number)
+	if err != nil {
+		return nil, err
+	}
+	if !isLatest {
+		return nil, ErrWeekNotLatest
+	}
+
+	result := &RevertWeekResult{
+		WeekID:     weekID,
+		FromStatus: week.Status,
+		ToStatus:   toStatus,
+	}
+
+	// leaving final
+	if week.Status == StatusFinal {
+		res, err := tx.Exec(`DELETE FROM public.season_standings WHERE week_id = $1`, weekID)
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected()
+		result.StandingsDeleted = int(rows)
+
+		if _, err := tx.Exec(`UPDATE public.weeks SET closed_at = NULL WHERE id = $1`, weekID); err != nil {
+			return nil, err
+		}
+	}
+
+	// leaving scored
+	if isWeekPast(week.Status, StatusScored) && !isWeekPast(toStatus, StatusScored) {
+		res, err := tx.Exec(`DELETE FROM public.week_results WHERE week_id = $1`, weekID)
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected()
+		result.ResultsDeleted = int(rows)
+	}
+
+	// survivor eliminations are applied while the week is in picks_results_calculated, on the way into scored.
+	// A week left in picks_results_calculated (scoring failed) can already have them, so leaving either status
+	// clears them.  Advancing again applies them from the new grades
+	if isWeekPast(week.Status, StatusPicksResultsCalculated) && !isWeekPast(toStatus, StatusScored) {
+		res, err := tx.Exec(`
+			UPDATE public.season_participants
+			SET eliminated_week_id = NULL,
+				eliminated_at = NULL
+			WHERE eliminated_week_id = $1
+		`, weekID)
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected()
+		result.UsersUneliminated = int(rows)
+	}
+
+	// leaving picks_results_calculated
+	if isWeekPast(week.Status, StatusPicksResultsCalculated) && !isWeekPast(toStatus, StatusPicksResultsCalculated) {
+		res, err := tx.Exec(`
+			UPDATE public.picks
+			SET is_correct = NULL,
+				is_push = false,
+				total_is_correct = NULL,
+				total_is_push = false,
+				calculated_at = NULL
+			WHERE week_id = $1
+		`, weekID)
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected()
+		result.PicksUngraded = int(rows)
+	}
+
+	// leaving active
+	if isWeekPast(week.Status, StatusActive) && !isWeekPast(toStatus, StatusActive) {
+		if _, err := tx.Exec(`UPDATE public.weeks SET activated_at = NULL WHERE id = $1`, weekID); err != nil {
+			return nil, err
+		}
+		result.ActivationCleared = true
+	}
+
+	if err := UpdateWeekStatus(tx, weekID, toStatus); err != nil {
+		return nil, err
+	}
+
+	revertID, err := id.New()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO public.week_reverts
+		(id, week_id, from_status, to_status, reason, reverted_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, revertID, weekID, week.Status, toStatus, reason, actingUserID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warn(
+		"week reverted",
+		"week_id", weekID,
+		"from_status", week.Status,
+		"to_status", toStatus,
+		"reason", reason,
+		"reverted_by", actingUserID,
+		"standings_deleted", result.StandingsDeleted,
+		"results_deleted", result.ResultsDeleted,
+		"users_uneliminated", result.UsersUneliminated,
+		"picks_ungraded", result.PicksUngraded,
+	)
+
+	return result, nil
+}
@@ -1,24 +1,130 @@
 package settings
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 )
 
+// pick_lock_rule values
+const (
+	PickLockPerGame   = "per_game"   // each game locks pick_cutoff_minutes before its kickoff
+	PickLockSunday1PM = "sunday_1pm" // per game, but everything still open locks at Sunday 1:00 PM competition time
+)
+
 type Settings struct {
-	PickCutoffMinutes          int    `db:"pick_cutoff_minutes"`
-	AllowPickEdits             bool   `db:"allow_pick_edits"`
-	PointsPerCorrectPick       int    `db:"points_per_correct_pick"`
-	CompetitionTimezone        string `db:"competition_timezone"`
-	AllowCommissionerOverrides bool   `db:"allow_commissioner_overrides"`
-	AllowPicksAfterKickoff     bool   `db:"allow_picks_after_kickoff"`
+	PickCutoffMinutes          int    `db:"pick_cutoff_minutes" json:"pick_cutoff_minutes"`
+	AllowPickEdits             bool   `db:"allow_pick_edits" json:"allow_pick_edits"`
+	PointsPerCorrectPick       int    `db:"points_per_correct_pick" json:"points_per_correct_pick"`
+	CompetitionTimezone        string `db:"competition_timezone" json:"competition_timezone"`
+	AllowCommissionerOverrides bool   `db:"allow_commissioner_overrides" json:"allow_commissioner_overrides"`
+	AllowPicksAfterKickoff     bool   `db:"allow_picks_after_kickoff" json:"allow_picks_after_kickoff"`
+	PickLockRule               string `db:"pick_lock_rule" json:"pick_lock_rule"`
 }
 
 // just gets all the global settings
 func Get(db *sqlx.DB) (*Settings, error) {
 	var s Settings
-	err := db.Get(&s, `SELECT pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick, competition_timezone, allow_commissioner_overrides, allow_picks_after_kickoff FROM settings LIMIT 1`)
+	err := db.Get(&s, `SELECT pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick, competition_timezone, allow_commissioner_overrides, allow_picks_after_kickoff, pick_lock_rule FROM settings LIMIT 1`)
 	if err != nil {
 		return nil, err
 	}
 	return &s, nil
 }
+
+// IsValidPickLockRule checks the rule is one we know how to enforce
+func IsValidPickLockRule(rule string) bool {
+	return rule == PickLockPerGame || rule == PickLockSunday1PM
+}
+
+// Location loads the competition timezone.  It's validated when saved, but UTC is used if it somehow can't load
+func (s *Settings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.CompetitionTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// the rules a season plays by: its snapshot, then its league's settings, then the global row.
+// Columns the season doesn't snapshot (the timezone) come from the league or the global row
+const effectiveSettingsQuery = `
+	SELECT
+		COALESCE(ss.pick_cutoff_minutes, ls.pick_cutoff_minutes, g.pick_cutoff_minutes) AS pick_cutoff_minutes,
+		COALESCE(ss.allow_pick_edits, ls.allow_pick_edits, g.allow_pick_edits) AS allow_pick_edits,
+		COALESCE(ss.points_per_correct_pick, ls.points_per_correct_pick, g.points_per_correct_pick) AS points_per_correct_pick,
+		COALESCE(ls.competition_timezone, g.competition_timezone) AS competition_timezone,
+		COALESCE(ss.allow_commissioner_overrides, ls.allow_commissioner_overrides, g.allow_commissioner_overrides) AS allow_commissioner_overrides,
+		COALESCE(ss.allow_picks_after_kickoff, ls.allow_picks_after_kickoff, g.allow_picks_after_kickoff) AS allow_picks_after_kickoff,
+		COALESCE(ss.pick_lock_rule, ls.pick_lock_rule, g.pick_lock_rule) AS pick_lock_rule
+	FROM (SELECT * FROM settings LIMIT 1) g
+	LEFT JOIN seasons s ON s.id = %s
+	LEFT JOIN league_settings ls ON ls.league_id = s.league_id
+	LEFT JOIN season_settings ss ON ss.season_id = s.id
+`
+
+// GetForLeague returns a league's settings: its league_settings on top of the global settings.  New seasons in
+// the league start with these rules
+func GetForLeague(q sqlx.Queryer, leagueID string) (*Settings, error) {
+	var s Settings
+	err := sqlx.Get(q, &s, `
+		SELECT
+			COALESCE(ls.pick_cutoff_minutes, g.pick_cutoff_minutes) AS pick_cutoff_minutes,
+			COALESCE(ls.allow_pick_edits, g.allow_pick_edits) AS allow_pick_edits,
+			COALESCE(ls.points_per_correct_pick, g.points_per_correct_pick) AS points_per_correct_pick,
+			COALESCE(ls.competition_timezone, g.competition_timezone) AS competition_timezone,
+			COALESCE(ls.allow_commissioner_overrides, g.allow_commissioner_overrides) AS allow_commissioner_overrides,
+			COALESCE(ls.allow_picks_after_kickoff, g.allow_picks_after_kickoff) AS allow_picks_after_kickoff,
+			COALESCE(ls.pick_lock_rule, g.pick_lock_rule) AS pick_lock_rule
+		FROM (SELECT * FROM settings LIMIT 1) g
+		LEFT JOIN league_settings ls ON ls.league_id = $1
+	`, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetForSeason returns the settings a season plays by: its season_settings snapshot on top of its league's
+// settings and the global settings.  Seasons without a snapshot use the league's settings
+func GetForSeason(q sqlx.Queryer, seasonID string) (*Settings, error) {
+	var s Settings
+	if err := sqlx.Get(q, &s, fmt.Sprintf(effectiveSettingsQuery, "$1"), seasonID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetForWeek returns the settings for the season a week belongs to (see GetForSeason)
+func GetForWeek(q sqlx.Queryer, weekID string) (*Settings, error) {
+	var s Settings
+	if err := sqlx.Get(q, &s, fmt.Sprintf(effectiveSettingsQuery, "(SELECT season_id FROM weeks WHERE id = $1)"), weekID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SnapshotForSeason copies the current scoring and pick rules of a new season's league (see GetForLeague) to
+// the season.  The season has to be inserted first
+func SnapshotForSeason(e sqlx.Execer, seasonID string) error {
+	_, err := e.Exec(`
+		INSERT INTO season_settings (
+			season_id, pick_cutoff_minutes, allow_pick_edits, points_per_correct_pick,
+			allow_commissioner_overrides, allow_picks_after_kickoff, pick_lock_rule
+		)
+		SELECT
+			s.id,
+			COALESCE(ls.pick_cutoff_minutes, g.pick_cutoff_minutes),
+			COALESCE(ls.allow_pick_edits, g.allow_pick_edits),
+			COALESCE(ls.points_per_correct_pick, g.points_per_correct_pick),
+			COALESCE(ls.allow_commissioner_overrides, g.allow_commissioner_overrides),
+			COALESCE(ls.allow_picks_after_kickoff, g.allow_picks_after_kickoff),
+			COALESCE(ls.pick_lock_rule, g.pick_lock_rule)
+		FROM seasons s
+		CROSS JOIN (SELECT * FROM settings LIMIT 1) g
+		LEFT JOIN league_settings ls ON ls.league_id = s.league_id
+		WHERE s.id = $1
+	`, seasonID)
+	return err
+}
@@ -0,0 +1,33 @@
+-- Confidence-points pick mode.
+-- Seasons choose how correct picks are scored.  In confidence mode every pick in a week
+-- carries a unique confidence value (1..N) and a correct pick is worth that many points.
+
+CREATE TYPE "public"."scoring_mode" AS ENUM (
+    'standard',
+    'confidence'
+);
+
+
+ALTER TYPE "public"."scoring_mode" OWNER TO "postgres";
+
+
+ALTER TABLE "public"."seasons"
+    ADD COLUMN "scoring_mode" "public"."scoring_mode" DEFAULT 'standard'::"public"."scoring_mode" NOT NULL;
+
+
+COMMENT ON COLUMN "public"."seasons"."scoring_mode" IS 'How picks are scored: standard (flat points per correct pick) or confidence (correct picks worth their confidence value)';
+
+
+
+ALTER TABLE "public"."picks"
+    ADD COLUMN "confidence" integer,
+    ADD CONSTRAINT "picks_confidence_check" CHECK (("confidence" IS NULL) OR ("confidence" > 0));
+
+
+COMMENT ON COLUMN "public"."picks"."confidence" IS 'Confidence value (1..N) for confidence-mode seasons. NULL in standard seasons';
+
+
+
+-- Deferred so that a user can swap two confidence values in a single submission
+ALTER TABLE ONLY "public"."picks"
+    ADD CONSTRAINT "picks_user_week_confidence_key" UNIQUE ("user_id", "week_id", "confidence") DEFERRABLE INITIALLY DEFERRED;
@@ -0,0 +1,76 @@
+-- Survivor / eliminator pools.
+-- A survivor season runs alongside the regular pick'em season for the same year.  Each participant
+-- picks one team per week, can never reuse a team, and is eliminated on their first loss.
+
+CREATE TYPE "public"."season_type" AS ENUM (
+    'pickem',
+    'survivor'
+);
+
+
+ALTER TYPE "public"."season_type" OWNER TO "postgres";
+
+
+ALTER TABLE "public"."seasons"
+    ADD COLUMN "season_type" "public"."season_type" DEFAULT 'pickem'::"public"."season_type" NOT NULL,
+    ADD COLUMN "survivor_use_spread" boolean DEFAULT true NOT NULL;
+
+
+COMMENT ON COLUMN "public"."seasons"."season_type" IS 'pickem (pick every game) or survivor (one team per week, eliminated on first loss)';
+
+
+
+COMMENT ON COLUMN "public"."seasons"."survivor_use_spread" IS 'Survivor seasons only: picks are graded against the spread when true, straight-up when false';
+
+
+
+-- one pick'em and one survivor season can exist (and be active) for the same year
+ALTER TABLE ONLY "public"."seasons"
+    DROP CONSTRAINT "seasons_year_postseason_key";
+
+
+
+ALTER TABLE ONLY "public"."seasons"
+    ADD CONSTRAINT "seasons_year_postseason_type_key" UNIQUE ("year", "is_postseason", "season_type");
+
+
+
+DROP INDEX IF EXISTS "public"."unique_active_season";
+
+
+
+CREATE UNIQUE INDEX "unique_active_season" ON "public"."seasons" USING "btree" ("season_type") WHERE ("is_active" = true);
+
+
+
+COMMENT ON COLUMN "public"."seasons"."is_active" IS 'Only one season of each season_type can be active at a time';
+
+
+
+-- Each active season has its own active week now.  weeks_one_non_final_per_season still
+-- guarantees at most one active week per season.
+DROP INDEX IF EXISTS "public"."weeks_single_active";
+
+
+
+-- the pick'em and survivor seasons for a year import the same NFL games, so an external game id is only
+-- unique within a season
+DROP INDEX IF EXISTS "public"."games_external_game_id_uniq";
+
+
+
+CREATE UNIQUE INDEX "games_external_game_id_season_uniq" ON "public"."games" USING "btree" ("season_id", "external_game_id") WHERE ("external_game_id" IS NOT NULL);
+
+
+
+ALTER TABLE "public"."season_participants"
+    ADD COLUMN "eliminated_week_id" "text",
+    ADD COLUMN "eliminated_at" timestamp with time zone;
+
+
+COMMENT ON COLUMN "public"."season_participants"."eliminated_week_id" IS 'Survivor seasons only: the week the user was eliminated in. NULL while still alive';
+
+
+
+ALTER TABLE ONLY "public"."season_participants"
+    ADD CONSTRAINT "season_participants_eliminated_week_id_fkey" FOREIGN KEY ("eliminated_week_id") REFERENCES "public"."weeks"("id") ON DELETE SET NULL;
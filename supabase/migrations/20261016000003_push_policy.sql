@@ -0,0 +1,38 @@
+-- Configurable push scoring.
+-- A push is a game that lands exactly on the spread.  Each season chooses how a push is graded,
+-- and points become numeric so a push can be worth half a pick.
+
+CREATE TYPE "public"."push_policy" AS ENUM (
+    'loss',
+    'win',
+    'half',
+    'void'
+);
+
+
+ALTER TYPE "public"."push_policy" OWNER TO "postgres";
+
+
+ALTER TABLE "public"."seasons"
+    ADD COLUMN "push_policy" "public"."push_policy" DEFAULT 'loss'::"public"."push_policy" NOT NULL;
+
+
+COMMENT ON COLUMN "public"."seasons"."push_policy" IS 'How a pick on a pushed game is scored: loss (0 points), win (full points), half (half points) or void (game does not count)';
+
+
+
+ALTER TABLE "public"."picks"
+    ADD COLUMN "is_push" boolean DEFAULT false NOT NULL;
+
+
+COMMENT ON COLUMN "public"."picks"."is_push" IS 'True when the picked game landed exactly on the spread. is_correct is then set by the season push_policy (NULL for half and void)';
+
+
+
+ALTER TABLE "public"."week_results"
+    ALTER COLUMN "points" TYPE numeric(10,1);
+
+
+
+ALTER TABLE "public"."season_standings"
+    ALTER COLUMN "points" TYPE numeric(10,1);
@@ -0,0 +1,40 @@
+-- Over/under (totals) picks.
+-- The commissioner can turn on a totals market for a week.  Each game then carries a total and
+-- users pick over or under in addition to the side.
+
+CREATE TYPE "public"."total_pick" AS ENUM (
+    'over',
+    'under'
+);
+
+
+ALTER TYPE "public"."total_pick" OWNER TO "postgres";
+
+
+ALTER TABLE "public"."weeks"
+    ADD COLUMN "totals_enabled" boolean DEFAULT false NOT NULL;
+
+
+COMMENT ON COLUMN "public"."weeks"."totals_enabled" IS 'When true every game in the week also has an over/under pick';
+
+
+
+ALTER TABLE "public"."games"
+    ADD COLUMN "total" numeric(4,1);
+
+
+COMMENT ON COLUMN "public"."games"."total" IS 'Over/under line for combined points. Only used when the week has totals enabled';
+
+
+
+ALTER TABLE "public"."picks"
+    ADD COLUMN "total_pick" "public"."total_pick",
+    ADD COLUMN "total_is_correct" boolean,
+    ADD COLUMN "total_is_push" boolean DEFAULT false NOT NULL;
+
+
+COMMENT ON COLUMN "public"."picks"."total_pick" IS 'over or under. NULL when the week has no totals market';
+
+
+
+COMMENT ON COLUMN "public"."picks"."total_is_push" IS 'True when the combined score landed exactly on the total. total_is_correct is then set by the season push_policy';
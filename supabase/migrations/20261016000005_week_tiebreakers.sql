@@ -0,0 +1,71 @@
+-- Weekly tiebreaker.
+-- When locking picks a user predicts the combined score of the last game of the week.  Users tied
+-- on points are ranked by how close their prediction was.
+
+CREATE TABLE IF NOT EXISTS "public"."week_tiebreakers" (
+    "id" "text" NOT NULL,
+    "user_id" "uuid" NOT NULL,
+    "week_id" "text" NOT NULL,
+    "game_id" "text" NOT NULL,
+    "predicted_total" integer NOT NULL,
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "week_tiebreakers_predicted_total_check" CHECK (("predicted_total" >= 0))
+);
+
+
+ALTER TABLE "public"."week_tiebreakers" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."week_tiebreakers" IS 'Each user''s predicted combined score for the last game of a week, used to break ties in week_results';
+
+
+
+COMMENT ON COLUMN "public"."week_tiebreakers"."game_id" IS 'The tiebreaker game (latest kickoff in the week) at the time the prediction was made';
+
+
+
+ALTER TABLE ONLY "public"."week_tiebreakers"
+    ADD CONSTRAINT "week_tiebreakers_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."week_tiebreakers"
+    ADD CONSTRAINT "week_tiebreakers_user_id_week_id_key" UNIQUE ("user_id", "week_id");
+
+
+
+ALTER TABLE ONLY "public"."week_tiebreakers"
+    ADD CONSTRAINT "week_tiebreakers_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."profiles"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."week_tiebreakers"
+    ADD CONSTRAINT "week_tiebreakers_week_id_fkey" FOREIGN KEY ("week_id") REFERENCES "public"."weeks"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."week_tiebreakers"
+    ADD CONSTRAINT "week_tiebreakers_game_id_fkey" FOREIGN KEY ("game_id") REFERENCES "public"."games"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE "public"."week_tiebreakers" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."week_tiebreakers" TO "anon";
+GRANT ALL ON TABLE "public"."week_tiebreakers" TO "authenticated";
+GRANT ALL ON TABLE "public"."week_tiebreakers" TO "service_role";
+
+
+
+CREATE OR REPLACE TRIGGER "update_week_tiebreakers_updated_at" BEFORE UPDATE ON "public"."week_tiebreakers" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."week_results"
+    ADD COLUMN "tiebreaker_diff" integer;
+
+
+COMMENT ON COLUMN "public"."week_results"."tiebreaker_diff" IS 'How far the user''s tiebreaker prediction was from the actual combined score. NULL if no prediction';
@@ -0,0 +1,53 @@
+-- Week state reverts.
+-- A commissioner can move a week back to an earlier status (for example to fix a wrong spread after
+-- activation).  Every revert needs a reason and is recorded here.
+
+CREATE TABLE IF NOT EXISTS "public"."week_reverts" (
+    "id" "text" NOT NULL,
+    "week_id" "text" NOT NULL,
+    "from_status" "public"."week_status" NOT NULL,
+    "to_status" "public"."week_status" NOT NULL,
+    "reason" "text" NOT NULL,
+    "reverted_by" "uuid" NOT NULL,
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "week_reverts_reason_check" CHECK (("length"(TRIM(BOTH FROM "reason")) > 0))
+);
+
+
+ALTER TABLE "public"."week_reverts" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."week_reverts" IS 'Audit log of commissioner week status reverts';
+
+
+
+COMMENT ON COLUMN "public"."week_reverts"."reason" IS 'Why the week was reverted. Required';
+
+
+
+ALTER TABLE ONLY "public"."week_reverts"
+    ADD CONSTRAINT "week_reverts_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."week_reverts"
+    ADD CONSTRAINT "week_reverts_week_id_fkey" FOREIGN KEY ("week_id") REFERENCES "public"."weeks"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."week_reverts"
+    ADD CONSTRAINT "week_reverts_reverted_by_fkey" FOREIGN KEY ("reverted_by") REFERENCES "public"."profiles"("id");
+
+
+
+CREATE INDEX "week_reverts_week_id_idx" ON "public"."week_reverts" USING "btree" ("week_id");
+
+
+
+ALTER TABLE "public"."week_reverts" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."week_reverts" TO "anon";
+GRANT ALL ON TABLE "public"."week_reverts" TO "authenticated";
+GRANT ALL ON TABLE "public"."week_reverts" TO "service_role";
@@ -0,0 +1,30 @@
+-- Postponed, cancelled and voided games.
+-- The provider can report a game as postponed or cancelled.  A voided game (cancelled games are voided
+-- automatically, anything else by a commissioner) doesn't count for anyone's picks and doesn't hold up
+-- the week from reaching final.
+
+ALTER TYPE "public"."game_status" ADD VALUE IF NOT EXISTS 'postponed';
+ALTER TYPE "public"."game_status" ADD VALUE IF NOT EXISTS 'cancelled';
+
+
+
+ALTER TABLE "public"."games"
+    ADD COLUMN "voided_at" timestamp with time zone,
+    ADD COLUMN "void_reason" "text",
+    ADD COLUMN "voided_by" "uuid";
+
+
+COMMENT ON COLUMN "public"."games"."voided_at" IS 'When the game was voided. Voided games are excluded from scoring and the all games final check';
+
+
+
+COMMENT ON COLUMN "public"."games"."void_reason" IS 'Why the game was voided';
+
+
+
+COMMENT ON COLUMN "public"."games"."voided_by" IS 'Commissioner who voided the game. NULL when voided automatically because the provider cancelled it';
+
+
+
+ALTER TABLE ONLY "public"."games"
+    ADD CONSTRAINT "games_voided_by_fkey" FOREIGN KEY ("voided_by") REFERENCES "public"."profiles"("id");
@@ -0,0 +1,14 @@
+-- Live scores.
+-- Scores for games in progress are imported as they happen, along with the quarter and game clock,
+-- so the active week can show provisional results.
+
+ALTER TABLE "public"."games"
+    ADD COLUMN "period" smallint,
+    ADD COLUMN "clock" "text";
+
+
+COMMENT ON COLUMN "public"."games"."period" IS 'Current quarter for games in progress (5 and up is overtime). NULL before kickoff';
+
+
+
+COMMENT ON COLUMN "public"."games"."clock" IS 'Game clock for games in progress as reported by the provider. Cleared when the game is final';
@@ -0,0 +1,53 @@
+-- Spread line history.
+-- Every bookmaker's line is kept each time spreads are fetched, so line movement can be shown per game
+-- and commissioners can see which games moved a lot after the week was activated.
+
+CREATE TABLE IF NOT EXISTS "public"."spread_history" (
+    "id" "text" NOT NULL,
+    "game_id" "text" NOT NULL,
+    "bookmaker" "text" NOT NULL,
+    "bookmaker_title" "text" NOT NULL,
+    "home_spread" numeric(4,1) NOT NULL,
+    "total" numeric(4,1),
+    "last_update" timestamp with time zone NOT NULL,
+    "fetched_at" timestamp with time zone DEFAULT "now"() NOT NULL
+);
+
+
+ALTER TABLE "public"."spread_history" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."spread_history" IS 'Every bookmaker line seen for a game, one row per bookmaker per line update';
+
+
+
+COMMENT ON COLUMN "public"."spread_history"."bookmaker" IS 'Odds API bookmaker key (draftkings, fanduel, ...)';
+
+
+
+COMMENT ON COLUMN "public"."spread_history"."last_update" IS 'When the bookmaker last updated the line, from the Odds API';
+
+
+
+ALTER TABLE ONLY "public"."spread_history"
+    ADD CONSTRAINT "spread_history_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."spread_history"
+    ADD CONSTRAINT "spread_history_game_id_bookmaker_last_update_key" UNIQUE ("game_id", "bookmaker", "last_update");
+
+
+
+ALTER TABLE ONLY "public"."spread_history"
+    ADD CONSTRAINT "spread_history_game_id_fkey" FOREIGN KEY ("game_id") REFERENCES "public"."games"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE "public"."spread_history" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."spread_history" TO "anon";
+GRANT ALL ON TABLE "public"."spread_history" TO "authenticated";
+GRANT ALL ON TABLE "public"."spread_history" TO "service_role";
@@ -0,0 +1,56 @@
+-- Team aliases.
+-- Providers don't always name teams the way we do (the Odds API uses full names, some feeds still send WAS
+-- for Washington).  Each alias maps a provider's name for a team to teams.id, so a rename or relocation is
+-- a data change instead of a deploy.  provider 'any' applies to every provider.
+
+CREATE TABLE IF NOT EXISTS "public"."team_aliases" (
+    "id" "text" NOT NULL,
+    "provider" "text" NOT NULL,
+    "alias" "text" NOT NULL,
+    "team_id" "text" NOT NULL,
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "team_aliases_alias_check" CHECK (("length"(TRIM(BOTH FROM "alias")) > 0))
+);
+
+
+ALTER TABLE "public"."team_aliases" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."team_aliases" IS 'Provider team names and abbreviations mapped to our teams';
+
+
+
+COMMENT ON COLUMN "public"."team_aliases"."provider" IS 'odds, balldontlie, espn, or any for every provider';
+
+
+
+COMMENT ON COLUMN "public"."team_aliases"."alias" IS 'The provider''s name or abbreviation for the team. Matched case-insensitively';
+
+
+
+ALTER TABLE ONLY "public"."team_aliases"
+    ADD CONSTRAINT "team_aliases_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."team_aliases"
+    ADD CONSTRAINT "team_aliases_team_id_fkey" FOREIGN KEY ("team_id") REFERENCES "public"."teams"("id") ON DELETE CASCADE;
+
+
+
+CREATE UNIQUE INDEX "team_aliases_provider_alias_key" ON "public"."team_aliases" USING "btree" ("provider", "lower"("alias"));
+
+
+
+CREATE OR REPLACE TRIGGER "update_team_aliases_updated_at" BEFORE UPDATE ON "public"."team_aliases" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."team_aliases" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."team_aliases" TO "anon";
+GRANT ALL ON TABLE "public"."team_aliases" TO "authenticated";
+GRANT ALL ON TABLE "public"."team_aliases" TO "service_role";
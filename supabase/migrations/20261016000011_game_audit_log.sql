@@ -0,0 +1,58 @@
+-- Game audit log.
+-- Commissioners can add, edit and delete games before a week is activated.  Every change is recorded
+-- with the game before and after.  game_id has no foreign key so deleted games keep their history.
+
+CREATE TABLE IF NOT EXISTS "public"."game_audit_log" (
+    "id" "text" NOT NULL,
+    "game_id" "text" NOT NULL,
+    "week_id" "text" NOT NULL,
+    "action" "text" NOT NULL,
+    "before" "jsonb",
+    "after" "jsonb",
+    "changed_by" "uuid" NOT NULL,
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "game_audit_log_action_check" CHECK (("action" = ANY (ARRAY['create'::"text", 'update'::"text", 'delete'::"text"])))
+);
+
+
+ALTER TABLE "public"."game_audit_log" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."game_audit_log" IS 'Audit log of commissioner game creates, edits and deletes';
+
+
+
+COMMENT ON COLUMN "public"."game_audit_log"."before" IS 'The game before the change. NULL for creates';
+
+
+
+COMMENT ON COLUMN "public"."game_audit_log"."after" IS 'The game after the change. NULL for deletes';
+
+
+
+ALTER TABLE ONLY "public"."game_audit_log"
+    ADD CONSTRAINT "game_audit_log_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."game_audit_log"
+    ADD CONSTRAINT "game_audit_log_week_id_fkey" FOREIGN KEY ("week_id") REFERENCES "public"."weeks"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."game_audit_log"
+    ADD CONSTRAINT "game_audit_log_changed_by_fkey" FOREIGN KEY ("changed_by") REFERENCES "public"."profiles"("id");
+
+
+
+CREATE INDEX "game_audit_log_week_id_idx" ON "public"."game_audit_log" USING "btree" ("week_id", "created_at");
+
+
+
+ALTER TABLE "public"."game_audit_log" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."game_audit_log" TO "anon";
+GRANT ALL ON TABLE "public"."game_audit_log" TO "authenticated";
+GRANT ALL ON TABLE "public"."game_audit_log" TO "service_role";
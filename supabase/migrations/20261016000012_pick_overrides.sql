@@ -0,0 +1,25 @@
+-- Commissioner pick overrides.
+-- When allow_commissioner_overrides is on, a commissioner can set or clear a pick for a user (someone who
+-- texted their picks in).  The pick records who overrode it, when and why, and is locked so the user can't
+-- change it back.
+
+ALTER TABLE "public"."picks"
+    ADD COLUMN "overridden_by" "uuid",
+    ADD COLUMN "overridden_at" timestamp with time zone,
+    ADD COLUMN "override_reason" "text";
+
+
+COMMENT ON COLUMN "public"."picks"."overridden_by" IS 'Commissioner who last set or cleared this pick for the user. NULL for picks the user made';
+
+
+
+COMMENT ON COLUMN "public"."picks"."overridden_at" IS 'When the pick was last overridden';
+
+
+
+COMMENT ON COLUMN "public"."picks"."override_reason" IS 'Why the commissioner overrode the pick';
+
+
+
+ALTER TABLE ONLY "public"."picks"
+    ADD CONSTRAINT "picks_overridden_by_fkey" FOREIGN KEY ("overridden_by") REFERENCES "public"."profiles"("id");
@@ -0,0 +1,11 @@
+-- Pick lock rules.
+-- per_game locks each game pick_cutoff_minutes before its own kickoff.  sunday_1pm also locks every pick
+-- that hasn't locked yet at 1:00 PM Sunday in the competition timezone, so only Thursday, Friday, Saturday
+-- and early Sunday (international) games lock on their own.
+
+ALTER TABLE "public"."settings"
+    ADD COLUMN "pick_lock_rule" "text" DEFAULT 'per_game'::"text" NOT NULL,
+    ADD CONSTRAINT "settings_pick_lock_rule_check" CHECK (("pick_lock_rule" = ANY (ARRAY['per_game'::"text", 'sunday_1pm'::"text"])));
+
+
+COMMENT ON COLUMN "public"."settings"."pick_lock_rule" IS 'How picks lock: per_game (pick_cutoff_minutes before each kickoff) or sunday_1pm (per game, but no later than Sunday 1:00 PM competition time)';
@@ -0,0 +1,60 @@
+-- Per-season rules.
+-- Scoring and pick rules are copied from the global settings when a season is created, so changing a global
+-- setting mid-year only affects seasons created afterwards.  Commissioners can change a season's rules until
+-- its first week is activated.  competition_timezone and debug_mode stay global.
+
+CREATE TABLE IF NOT EXISTS "public"."season_settings" (
+    "season_id" "text" NOT NULL,
+    "pick_cutoff_minutes" integer NOT NULL,
+    "allow_pick_edits" boolean NOT NULL,
+    "points_per_correct_pick" integer NOT NULL,
+    "allow_commissioner_overrides" boolean NOT NULL,
+    "allow_picks_after_kickoff" boolean NOT NULL,
+    "pick_lock_rule" "text" NOT NULL,
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "season_settings_pick_lock_rule_check" CHECK (("pick_lock_rule" = ANY (ARRAY['per_game'::"text", 'sunday_1pm'::"text"])))
+);
+
+
+ALTER TABLE "public"."season_settings" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."season_settings" IS 'Scoring and pick rules for a season, snapshotted from settings when the season is created';
+
+
+
+ALTER TABLE ONLY "public"."season_settings"
+    ADD CONSTRAINT "season_settings_pkey" PRIMARY KEY ("season_id");
+
+
+
+ALTER TABLE ONLY "public"."season_settings"
+    ADD CONSTRAINT "season_settings_season_id_fkey" FOREIGN KEY ("season_id") REFERENCES "public"."seasons"("id") ON DELETE CASCADE;
+
+
+
+CREATE OR REPLACE TRIGGER "update_season_settings_updated_at" BEFORE UPDATE ON "public"."season_settings" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."season_settings" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."season_settings" TO "anon";
+GRANT ALL ON TABLE "public"."season_settings" TO "authenticated";
+GRANT ALL ON TABLE "public"."season_settings" TO "service_role";
+
+
+
+-- existing seasons keep playing by the current global rules
+INSERT INTO "public"."season_settings" (
+    "season_id", "pick_cutoff_minutes", "allow_pick_edits", "points_per_correct_pick",
+    "allow_commissioner_overrides", "allow_picks_after_kickoff", "pick_lock_rule"
+)
+SELECT s."id", g."pick_cutoff_minutes", g."allow_pick_edits", g."points_per_correct_pick",
+    g."allow_commissioner_overrides", g."allow_picks_after_kickoff", g."pick_lock_rule"
+FROM "public"."seasons" s
+CROSS JOIN (SELECT * FROM "public"."settings" LIMIT 1) g
+ON CONFLICT ("season_id") DO NOTHING;
@@ -0,0 +1,180 @@
+-- Leagues.
+-- A league owns its seasons (and through them participants, season settings, weeks and games) so separate
+-- groups can run their own pools on the same deployment and NFL week.  league_members gives each user a
+-- role in a league (owner, commissioner or member); global commissioners and admins still manage everything.
+-- Everything that existed before leagues moves to a default league.
+
+CREATE TABLE IF NOT EXISTS "public"."leagues" (
+    "id" "text" NOT NULL,
+    "name" "text" NOT NULL,
+    "created_by" "uuid",
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "leagues_name_check" CHECK (("length"(TRIM(BOTH FROM "name")) > 0))
+);
+
+
+ALTER TABLE "public"."leagues" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."leagues" IS 'A group running its own seasons. Seasons, participants and season settings belong to a league';
+
+
+
+COMMENT ON COLUMN "public"."leagues"."created_by" IS 'User who created the league. NULL for the default league';
+
+
+
+CREATE TABLE IF NOT EXISTS "public"."league_members" (
+    "league_id" "text" NOT NULL,
+    "user_id" "uuid" NOT NULL,
+    "role" "text" DEFAULT 'member'::"text" NOT NULL,
+    "joined_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "league_members_role_check" CHECK (("role" = ANY (ARRAY['owner'::"text", 'commissioner'::"text", 'member'::"text"])))
+);
+
+
+ALTER TABLE "public"."league_members" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."league_members" IS 'Users in a league and their league role';
+
+
+
+COMMENT ON COLUMN "public"."league_members"."role" IS 'owner (manages members and commissioners), commissioner (runs seasons) or member';
+
+
+
+ALTER TABLE ONLY "public"."leagues"
+    ADD CONSTRAINT "leagues_pkey" PRIMARY KEY ("id");
+
+
+
+ALTER TABLE ONLY "public"."leagues"
+    ADD CONSTRAINT "leagues_created_by_fkey" FOREIGN KEY ("created_by") REFERENCES "public"."profiles"("id") ON DELETE SET NULL;
+
+
+
+ALTER TABLE ONLY "public"."league_members"
+    ADD CONSTRAINT "league_members_pkey" PRIMARY KEY ("league_id", "user_id");
+
+
+
+ALTER TABLE ONLY "public"."league_members"
+    ADD CONSTRAINT "league_members_league_id_fkey" FOREIGN KEY ("league_id") REFERENCES "public"."leagues"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."league_members"
+    ADD CONSTRAINT "league_members_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."profiles"("id") ON DELETE CASCADE;
+
+
+
+CREATE INDEX "league_members_user_id_idx" ON "public"."league_members" USING "btree" ("user_id");
+
+
+
+CREATE OR REPLACE TRIGGER "update_leagues_updated_at" BEFORE UPDATE ON "public"."leagues" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."leagues" ENABLE ROW LEVEL SECURITY;
+
+
+
+ALTER TABLE "public"."league_members" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."leagues" TO "anon";
+GRANT ALL ON TABLE "public"."leagues" TO "authenticated";
+GRANT ALL ON TABLE "public"."leagues" TO "service_role";
+
+
+
+GRANT ALL ON TABLE "public"."league_members" TO "anon";
+GRANT ALL ON TABLE "public"."league_members" TO "authenticated";
+GRANT ALL ON TABLE "public"."league_members" TO "service_role";
+
+
+
+-- the default league gets everything that existed before leagues.  Every current user is a member and
+-- global commissioners are its commissioners
+INSERT INTO "public"."leagues" ("id", "name")
+VALUES ('00000000000000000000000000', 'Send Your Picks')
+ON CONFLICT ("id") DO NOTHING;
+
+
+
+INSERT INTO "public"."league_members" ("league_id", "user_id", "role")
+SELECT '00000000000000000000000000',
+    "id",
+    CASE WHEN "role" IN ('commissioner', 'admin') THEN 'commissioner' ELSE 'member' END
+FROM "public"."profiles"
+ON CONFLICT ("league_id", "user_id") DO NOTHING;
+
+
+
+
+-- new users join the default league too, so the seasons in it stay visible to everyone who signs up
+CREATE OR REPLACE FUNCTION "public"."handle_new_user"() RETURNS "trigger"
+    LANGUAGE "plpgsql" SECURITY DEFINER
+    SET "search_path" TO ''
+    AS $$
+BEGIN
+  INSERT INTO public.profiles (id, username, role)
+  VALUES (NEW.id, NEW.email, 'user');
+  INSERT INTO public.league_members (league_id, user_id, role)
+  VALUES ('00000000000000000000000000', NEW.id, 'member')
+  ON CONFLICT (league_id, user_id) DO NOTHING;
+  RETURN NEW;
+END;
+$$;
+
+
+
+ALTER TABLE "public"."seasons"
+    ADD COLUMN "league_id" "text" DEFAULT '00000000000000000000000000'::"text" NOT NULL;
+
+
+COMMENT ON COLUMN "public"."seasons"."league_id" IS 'The league running this season';
+
+
+
+ALTER TABLE ONLY "public"."seasons"
+    ADD CONSTRAINT "seasons_league_id_fkey" FOREIGN KEY ("league_id") REFERENCES "public"."leagues"("id") ON DELETE CASCADE;
+
+
+
+CREATE INDEX "seasons_league_id_idx" ON "public"."seasons" USING "btree" ("league_id");
+
+
+
+-- seasons, active seasons and games are unique within a league instead of across the whole database
+ALTER TABLE ONLY "public"."seasons"
+    DROP CONSTRAINT "seasons_year_postseason_type_key";
+
+
+
+ALTER TABLE ONLY "public"."seasons"
+    ADD CONSTRAINT "seasons_league_year_postseason_type_key" UNIQUE ("league_id", "year", "is_postseason", "season_type");
+
+
+
+DROP INDEX IF EXISTS "public"."unique_active_season";
+
+
+
+CREATE UNIQUE INDEX "unique_active_season" ON "public"."seasons" USING "btree" ("league_id", "season_type") WHERE ("is_active" = true);
+
+
+
+COMMENT ON COLUMN "public"."seasons"."is_active" IS 'Only one season of each season_type can be active at a time in a league';
+
+
+
+DROP INDEX IF EXISTS "public"."games_external_game_id_uniq";
+
+
+
+CREATE UNIQUE INDEX "games_season_external_game_id_uniq" ON "public"."games" USING "btree" ("season_id", "external_game_id") WHERE ("external_game_id" IS NOT NULL);
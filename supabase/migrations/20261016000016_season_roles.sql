@@ -0,0 +1,90 @@
+-- Season roles.
+-- The global user_role claim made every commissioner a commissioner of every season.  season_roles gives each
+-- user one role per season: owner and co_commissioner manage the season, participant and spectator don't.
+-- season_participants still decides who makes picks.  Admins assign the roles.
+-- Existing seasons: the creator is the owner, global commissioners become co-commissioners so nobody loses
+-- access, and participants get the participant role.
+
+CREATE TABLE IF NOT EXISTS "public"."season_roles" (
+    "season_id" "text" NOT NULL,
+    "user_id" "uuid" NOT NULL,
+    "role" "text" NOT NULL,
+    "assigned_by" "uuid",
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "season_roles_role_check" CHECK (("role" = ANY (ARRAY['owner'::"text", 'co_commissioner'::"text", 'participant'::"text", 'spectator'::"text"])))
+);
+
+
+ALTER TABLE "public"."season_roles" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."season_roles" IS 'Each user''s role in a season. owner and co_commissioner can use the commissioner routes for the season';
+
+
+
+COMMENT ON COLUMN "public"."season_roles"."assigned_by" IS 'User who assigned the role. NULL for roles from the backfill';
+
+
+
+ALTER TABLE ONLY "public"."season_roles"
+    ADD CONSTRAINT "season_roles_pkey" PRIMARY KEY ("season_id", "user_id");
+
+
+
+ALTER TABLE ONLY "public"."season_roles"
+    ADD CONSTRAINT "season_roles_season_id_fkey" FOREIGN KEY ("season_id") REFERENCES "public"."seasons"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."season_roles"
+    ADD CONSTRAINT "season_roles_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "public"."profiles"("id") ON DELETE CASCADE;
+
+
+
+ALTER TABLE ONLY "public"."season_roles"
+    ADD CONSTRAINT "season_roles_assigned_by_fkey" FOREIGN KEY ("assigned_by") REFERENCES "public"."profiles"("id") ON DELETE SET NULL;
+
+
+
+CREATE INDEX "season_roles_user_id_idx" ON "public"."season_roles" USING "btree" ("user_id");
+
+
+
+CREATE OR REPLACE TRIGGER "update_season_roles_updated_at" BEFORE UPDATE ON "public"."season_roles" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."season_roles" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."season_roles" TO "anon";
+GRANT ALL ON TABLE "public"."season_roles" TO "authenticated";
+GRANT ALL ON TABLE "public"."season_roles" TO "service_role";
+
+
+
+-- whoever created a season owns it
+INSERT INTO "public"."season_roles" ("season_id", "user_id", "role")
+SELECT s."id", s."created_by", 'owner'
+FROM "public"."seasons" s
+JOIN "public"."profiles" p ON p."id" = s."created_by"
+ON CONFLICT ("season_id", "user_id") DO NOTHING;
+
+
+
+-- global commissioners keep managing the seasons that already exist
+INSERT INTO "public"."season_roles" ("season_id", "user_id", "role")
+SELECT s."id", p."id", 'co_commissioner'
+FROM "public"."seasons" s
+CROSS JOIN "public"."profiles" p
+WHERE p."role" = 'commissioner'
+ON CONFLICT ("season_id", "user_id") DO NOTHING;
+
+
+
+INSERT INTO "public"."season_roles" ("season_id", "user_id", "role")
+SELECT sp."season_id", sp."user_id", 'participant'
+FROM "public"."season_participants" sp
+ON CONFLICT ("season_id", "user_id") DO NOTHING;
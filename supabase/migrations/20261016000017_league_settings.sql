@@ -0,0 +1,49 @@
+-- League settings.
+-- Each league can set its own competition timezone and the scoring and pick rules its new seasons start with.
+-- A NULL column falls back to the global settings row, so leagues without a row (including the default league)
+-- keep using the global settings.  Seasons still snapshot their rules into season_settings when created, now
+-- from the league's values; the competition timezone isn't snapshotted and always comes from the league.
+
+CREATE TABLE IF NOT EXISTS "public"."league_settings" (
+    "league_id" "text" NOT NULL,
+    "pick_cutoff_minutes" integer,
+    "allow_pick_edits" boolean,
+    "points_per_correct_pick" integer,
+    "competition_timezone" "text",
+    "allow_commissioner_overrides" boolean,
+    "allow_picks_after_kickoff" boolean,
+    "pick_lock_rule" "text",
+    "created_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT "now"() NOT NULL,
+    CONSTRAINT "league_settings_pick_lock_rule_check" CHECK (("pick_lock_rule" = ANY (ARRAY['per_game'::"text", 'sunday_1pm'::"text"])))
+);
+
+
+ALTER TABLE "public"."league_settings" OWNER TO "postgres";
+
+
+COMMENT ON TABLE "public"."league_settings" IS 'A league''s timezone and the rules its new seasons start with. NULL columns use the global settings';
+
+
+
+ALTER TABLE ONLY "public"."league_settings"
+    ADD CONSTRAINT "league_settings_pkey" PRIMARY KEY ("league_id");
+
+
+
+ALTER TABLE ONLY "public"."league_settings"
+    ADD CONSTRAINT "league_settings_league_id_fkey" FOREIGN KEY ("league_id") REFERENCES "public"."leagues"("id") ON DELETE CASCADE;
+
+
+
+CREATE OR REPLACE TRIGGER "update_league_settings_updated_at" BEFORE UPDATE ON "public"."league_settings" FOR EACH ROW EXECUTE FUNCTION "public"."update_updated_at_column"();
+
+
+
+ALTER TABLE "public"."league_settings" ENABLE ROW LEVEL SECURITY;
+
+
+
+GRANT ALL ON TABLE "public"."league_settings" TO "anon";
+GRANT ALL ON TABLE "public"."league_settings" TO "authenticated";
+GRANT ALL ON TABLE "public"."league_settings" TO "service_role";